@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/alerts"
+	"github.com/andvarfolomeev/docker-notifier/internal/config"
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/stats"
+	"github.com/andvarfolomeev/docker-notifier/internal/telegram"
+	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
+)
+
+// endpoint is everything watching one Docker daemon: its own client, watcher
+// and monitors, so that a lost host does not affect the others.
+type endpoint struct {
+	client      *container.Client
+	watcher     *watcher.Watcher
+	monitor     *stats.Monitor
+	diskMonitor *stats.DiskMonitor
+}
+
+func startEndpoint(ctx context.Context, cfg *config.Config, e config.Endpoint, telegramClient *telegram.Client, log *slog.Logger) (*endpoint, error) {
+	log = log.With("endpoint", e.Name)
+
+	dclient, err := docker.NewFromHost(docker.HostOptions{
+		Host:      e.Host,
+		TLSVerify: cfg.TLSVerify,
+		CertPath:  e.CertPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Docker host %s: %w", e.Host, err)
+	}
+
+	containerClient, err := container.NewClient(dclient, &container.ClientOptions{
+		LabelEnabled:    cfg.LabelEnable,
+		HealthcheckOnly: cfg.HealthcheckOnly,
+		Host:            e.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Docker client: %w", err)
+	}
+
+	w, err := watcher.New(containerClient, watcherOptions(cfg, e))
+	if err != nil {
+		containerClient.Close()
+		return nil, fmt.Errorf("failed to initialize watcher: %w", err)
+	}
+
+	// check permissions
+	if _, err := containerClient.RunningContainers(ctx); err != nil {
+		var apiErr *docker.APIError
+		switch {
+		case errors.As(err, &apiErr) && errors.Is(err, docker.ErrPermission):
+			log.Error(containerPermissionDeniedLog, "err", err)
+		case errors.Is(err, docker.ErrPermission):
+			log.Error(socketPermissionDeniedLog, "err", err)
+		default:
+			log.Error("Failed to list containers", "err", err)
+		}
+	}
+
+	ep := &endpoint{client: containerClient, watcher: w}
+
+	w.Start(ctx)
+
+	routes := alerts.Routes{MinSeverity: cfg.MinSeverity, Chats: cfg.SeverityChats}
+	go alerts.RunDispatcher(ctx, w.C, telegramClient, routes, log)
+	go alerts.RunCrashDispatcher(ctx, w.Crashes, telegramClient, log)
+	go alerts.RunCrashLoopDispatcher(ctx, w.CrashLoops, telegramClient, log)
+	go alerts.RunHealthDispatcher(ctx, w.Health, telegramClient, log)
+	go alerts.RunHostDispatcher(ctx, w.Hosts, telegramClient, log)
+
+	thresholds := stats.Thresholds{
+		CPUPercent:    cfg.CPUThreshold,
+		MemoryPercent: cfg.MemoryThreshold,
+		Pids:          cfg.PidsThreshold,
+	}
+	if cfg.StatsInterval > 0 && thresholds != (stats.Thresholds{}) {
+		ep.monitor = stats.New(containerClient, &stats.MonitorOptions{
+			Interval:   cfg.StatsInterval,
+			Thresholds: thresholds,
+			For:        cfg.ThresholdDuration,
+		})
+		ep.monitor.Start(ctx)
+		go alerts.RunStatsDispatcher(ctx, ep.monitor.C, telegramClient, log)
+		log.Info("Resource usage monitor started", "interval", cfg.StatsInterval)
+	}
+
+	diskThresholds := stats.DiskThresholds{
+		Images:     cfg.ImagesThreshold,
+		Volumes:    cfg.VolumesThreshold,
+		BuildCache: cfg.BuildCacheThreshold,
+	}
+	if cfg.DiskInterval > 0 && diskThresholds != (stats.DiskThresholds{}) {
+		ep.diskMonitor = stats.NewDiskMonitor(containerClient, &stats.DiskMonitorOptions{
+			Interval:   cfg.DiskInterval,
+			Thresholds: diskThresholds,
+		})
+		ep.diskMonitor.Start(ctx)
+		go alerts.RunDiskDispatcher(ctx, ep.diskMonitor.C, telegramClient, log)
+		log.Info("Disk usage monitor started", "interval", cfg.DiskInterval)
+	}
+
+	if cfg.Follow {
+		log.Info("Watcher started, streaming logs", "host", e.Host)
+	} else {
+		log.Info("Watcher started, polling logs", "host", e.Host, "interval", cfg.Interval)
+	}
+
+	return ep, nil
+}
+
+// cleanup waits for the watcher and monitors to stop. The context passed to
+// startEndpoint must be canceled first.
+func (e *endpoint) cleanup() {
+	e.watcher.Cleanup()
+	if e.monitor != nil {
+		e.monitor.Cleanup()
+	}
+	if e.diskMonitor != nil {
+		e.diskMonitor.Cleanup()
+	}
+	e.client.Close()
+}
+
+func watcherOptions(cfg *config.Config, e config.Endpoint) *watcher.WatcherOptions {
+	return &watcher.WatcherOptions{
+		Interval:      time.Second * time.Duration(cfg.Interval),
+		ErrorPatterns: cfg.ErrorPatterns,
+		Follow:        cfg.Follow,
+
+		MaxLineLength: cfg.MaxLineLength,
+		Sanitize:      cfg.Sanitize,
+		PollMaxLines:  cfg.PollMaxLines,
+		PollMaxBytes:  cfg.PollMaxBytes,
+
+		ContextBefore:   cfg.ContextBefore,
+		ContextAfter:    cfg.ContextAfter,
+		Rules:           cfg.Rules,
+		RuleExcludes:    cfg.RuleExcludes,
+		ExcludePatterns: cfg.ExcludePatterns,
+
+		MultilinePreset:       cfg.MultilinePreset,
+		MultilineStart:        cfg.MultilineStart,
+		MultilineContinuation: cfg.MultilineContinuation,
+		MultilineMaxLines:     cfg.MultilineMaxLines,
+
+		LogFormat:   cfg.LogFormat,
+		FieldRules:  cfg.FieldRules,
+		AlertFields: cfg.AlertFields,
+
+		Redact:         cfg.Redact,
+		RedactPatterns: cfg.RedactPatterns,
+
+		CrashAlerts:   cfg.CrashAlerts,
+		CrashLogLines: cfg.CrashLogLines,
+
+		CrashLoopThreshold:      cfg.CrashLoopThreshold,
+		CrashLoopWindow:         cfg.CrashLoopWindow,
+		CrashLoopUpdateInterval: cfg.CrashLoopUpdateInterval,
+
+		HealthAlerts: cfg.HealthAlerts,
+
+		Host:       e.Name,
+		HostAlerts: cfg.HostAlerts,
+
+		SwarmServices: cfg.SwarmServices,
+	}
+}
@@ -4,22 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io/fs"
 	"log/slog"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
-	"time"
 
-	"github.com/andvarfolomeev/docker-notifier/internal/alerts"
 	"github.com/andvarfolomeev/docker-notifier/internal/config"
-	"github.com/andvarfolomeev/docker-notifier/internal/container"
-	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 	"github.com/andvarfolomeev/docker-notifier/internal/telegram"
-	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
 )
 
 const (
@@ -34,9 +26,16 @@ const (
 func main() {
 	cfg, err := config.Parse()
 	if err != nil {
+		if !errors.Is(err, config.ErrHelpRequested) {
+			fmt.Fprintln(os.Stderr, err)
+		}
 		os.Exit(1)
 	}
 
+	if cfg.TestLine != "" {
+		os.Exit(testLine(cfg))
+	}
+
 	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
 	})
@@ -46,59 +45,23 @@ func main() {
 
 	telegramClient := telegram.New(cfg.TelegramToken, cfg.TelegramChatID, &http.Client{})
 
-	dclient := docker.New(&http.Client{
-		Transport: &http.Transport{
-			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-				return net.Dial("unix", "/var/run/docker.sock")
-			},
-		},
-	})
-	containerClient, err := container.NewClient(dclient, &container.ClientOptions{
-		LabelEnabled: cfg.LabelEnable,
-	})
-
-	if err != nil {
-		if errors.Is(err, fs.ErrPermission) || strings.Contains(err.Error(), "permission denied") {
-			log.Error(socketPermissionDeniedLog)
-		} else {
-			log.Error("Failed to initialize Docker client", "err", err)
-		}
-		os.Exit(1)
-	}
-	defer containerClient.Close()
-
-	w, err := watcher.New(
-		containerClient,
-		&watcher.WatcherOptions{
-			Interval:      time.Second * time.Duration(cfg.Interval),
-			ErrorPatterns: cfg.ErrorPatterns,
-		},
-	)
-
-	if err != nil {
-		fmt.Println("Failed to initialize watcher", "err", err)
-		os.Exit(1)
-	}
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// check permissions
-	_, err = containerClient.RunningContainers(ctx)
-	if err != nil {
-		if errors.Is(err, fs.ErrPermission) || strings.Contains(err.Error(), "permission denied") {
-			log.Error(containerPermissionDeniedLog)
-		} else {
-			log.Error("Failed to list containers", "err", err)
+	endpoints := make([]*endpoint, 0, len(cfg.Endpoints))
+	for _, e := range cfg.Endpoints {
+		ep, err := startEndpoint(ctx, cfg, e, telegramClient, log)
+		if err != nil {
+			log.Error("Failed to start watching Docker endpoint", "endpoint", e.Name, "err", err)
+			cancel()
+			for _, started := range endpoints {
+				started.cleanup()
+			}
+			os.Exit(1)
 		}
-		cancel()
+		endpoints = append(endpoints, ep)
 	}
 
-	w.Start(ctx)
-
-	go alerts.RunDispatcher(ctx, w.C, telegramClient, log)
-
-	log.Info("Watcher started, polling logs", "interval", cfg.Interval)
 	if cfg.LabelEnable {
 		log.Info("Only containers with label %s=%s will be monitored", "com.andvarfolomeev.dockernotifier.enable", "true")
 	}
@@ -110,5 +73,7 @@ func main() {
 	log.Info("Received signal, shutting down...", "sig", sig)
 
 	cancel()
-	w.Cleanup()
+	for _, ep := range endpoints {
+		ep.cleanup()
+	}
 }
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/config"
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
+)
+
+// testLine prints how the rules decide on cfg.TestLine written to
+// cfg.TestStream. The exit code is 0 when the line would be reported and 1
+// when it would not.
+func testLine(cfg *config.Config) int {
+	w, err := watcher.New(nil, watcherOptions(cfg, config.Endpoint{}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid rules: %v\n", err)
+		return 2
+	}
+
+	line := []byte(cfg.TestLine)
+	if cfg.Sanitize {
+		line = logfilter.Sanitize(line)
+	}
+
+	decision := w.Evaluate(container.Container{}, cfg.TestStream, line)
+	fmt.Println(decision)
+
+	if !decision.Matched {
+		return 1
+	}
+	return 0
+}
@@ -5,25 +5,91 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/andvarfolomeev/docker-notifier/internal/stats"
 	"github.com/andvarfolomeev/docker-notifier/internal/telegram"
 	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
 )
 
 const timeout = 2 * time.Second
 
-func RunDispatcher(ctx context.Context, ch <-chan *watcher.MatchedLog, telegramClient *telegram.Client, log *slog.Logger) {
+func RunDispatcher(ctx context.Context, ch <-chan *watcher.MatchedLog, telegramClient *telegram.Client, routes Routes, log *slog.Logger) {
+	dispatchRouted(ctx, ch, func(match *watcher.MatchedLog) (string, string, bool) {
+		chatID, ok := routes.Route(match.Severity())
+		if !ok {
+			slog.Debug("Dropping match below the minimum severity", "containerID", match.Container.ID, "severity", match.Severity())
+			return "", "", false
+		}
+
+		slog.Info("Detected error pattern", "containerID", match.Container.ID, "severity", match.Severity())
+		return chatID, PrepareMessage(match), true
+	}, telegramClient, log)
+}
+
+func RunCrashDispatcher(ctx context.Context, ch <-chan *watcher.Crash, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(crash *watcher.Crash) string {
+		slog.Info("Detected container crash", "containerID", crash.Container.ID, "exitCode", crash.ExitCode)
+		return PrepareCrashMessage(crash)
+	}, telegramClient, log)
+}
+
+func RunCrashLoopDispatcher(ctx context.Context, ch <-chan *watcher.CrashLoop, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(loop *watcher.CrashLoop) string {
+		slog.Info("Detected crash loop", "containerID", loop.Container.ID, "restarts", loop.Restarts)
+		return PrepareCrashLoopMessage(loop)
+	}, telegramClient, log)
+}
+
+func RunHealthDispatcher(ctx context.Context, ch <-chan *watcher.HealthChange, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(change *watcher.HealthChange) string {
+		slog.Info("Detected health status change", "containerID", change.Container.ID, "status", change.Status)
+		return PrepareHealthMessage(change)
+	}, telegramClient, log)
+}
+
+func RunHostDispatcher(ctx context.Context, ch <-chan *watcher.HostStatus, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(status *watcher.HostStatus) string {
+		slog.Info("Detected Docker host status change", "host", status.Host, "lost", status.Lost)
+		return PrepareHostMessage(status)
+	}, telegramClient, log)
+}
+
+func RunStatsDispatcher(ctx context.Context, ch <-chan *stats.Breach, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(breach *stats.Breach) string {
+		slog.Info("Detected resource usage threshold breach", "containerID", breach.Container.ID, "metric", breach.Metric, "resolved", breach.Resolved)
+		return PrepareStatsMessage(breach)
+	}, telegramClient, log)
+}
+
+func RunDiskDispatcher(ctx context.Context, ch <-chan *stats.DiskBreach, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatch(ctx, ch, func(breach *stats.DiskBreach) string {
+		slog.Info("Detected disk usage threshold breach", "resource", breach.Resource, "size", breach.Size, "resolved", breach.Resolved)
+		return PrepareDiskMessage(breach)
+	}, telegramClient, log)
+}
+
+func dispatch[T any](ctx context.Context, ch <-chan T, format func(T) string, telegramClient *telegram.Client, log *slog.Logger) {
+	dispatchRouted(ctx, ch, func(item T) (string, string, bool) {
+		return "", format(item), true
+	}, telegramClient, log)
+}
+
+// dispatchRouted is dispatch for alerts that go to a chat of their own, see
+// telegram.Client.SendMessageTo, or are dropped when format returns false.
+func dispatchRouted[T any](ctx context.Context, ch <-chan T, format func(T) (string, string, bool), telegramClient *telegram.Client, log *slog.Logger) {
 	for {
 		select {
-		case match, ok := <-ch:
+		case item, ok := <-ch:
 			if !ok {
 				return
 			}
 
-			slog.Info("Detected error pattern", "containerID", match.Container.ID)
+			chatID, message, ok := format(item)
+			if !ok {
+				continue
+			}
 
-			message := PrepareMessage(match)
 			sendCtx, cancel := context.WithTimeout(ctx, timeout)
-			err := telegramClient.SendMessage(sendCtx, message)
+			err := telegramClient.SendMessageTo(sendCtx, chatID, message)
 			cancel()
 			if err != nil {
 				log.Error("Failed to send message", "err", err)
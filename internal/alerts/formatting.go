@@ -1,24 +1,357 @@
 package alerts
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/stats"
 	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
 )
 
+const maxLineLength = 100
+
+const (
+	maxTraceLineLength = 200
+	maxTraceLength     = 3000
+)
+
 func PrepareMessage(match *watcher.MatchedLog) string {
-	errorLine := match.Line.Content
-	if len(errorLine) > 100 {
-		errorLine = errorLine[:100]
-	}
+	first, trace, _ := bytes.Cut(match.Line.Content, []byte{'\n'})
+	errorLine := truncate(first, maxLineLength)
 
 	messageLines := []string{
-		fmt.Sprintf("🚨 Error detected!"),
-		fmt.Sprintf("Container ID = %s; Container name = %s", match.Container.ID, match.Container.Name),
-		fmt.Sprintf("Line: \"%s\"", errorLine),
+		matchTitle(match.Severity()),
+	}
+	messageLines = append(messageLines, containerLines(match.Container)...)
+	if match.Rule != nil {
+		messageLines = append(messageLines, fmt.Sprintf("Rule = %s; Severity = %s", match.Rule.Name, match.Rule.Severity))
+		if match.Rule.Description != "" {
+			messageLines = append(messageLines, match.Rule.Description)
+		}
+		if match.Count > 0 {
+			messageLines = append(messageLines, fmt.Sprintf("Matches = %d in %s", match.Count, match.Rule.Window))
+		}
+	}
+	if match.Line.Stream != 0 {
+		messageLines = append(messageLines, fmt.Sprintf("Stream = %s", match.Line.Stream))
+	}
+	if len(match.Line.Fields) > 0 {
+		for _, field := range match.Line.Fields {
+			messageLines = append(messageLines, fmt.Sprintf("%s: \"%s\"", field.Key, truncate([]byte(field.Value), maxLineLength)))
+		}
+	} else {
+		messageLines = append(messageLines, fmt.Sprintf("Line: \"%s\"", errorLine))
+		if len(trace) > 0 {
+			messageLines = append(messageLines, "Trace:")
+			messageLines = append(messageLines, traceLines(trace, match.Line.Omitted)...)
+		}
+	}
+	if len(match.Context) > 0 {
+		messageLines = append(messageLines, "Context:")
+		messageLines = append(messageLines, contextLines(match.Context)...)
+	}
+	if len(match.Samples) > 0 {
+		messageLines = append(messageLines, "Earlier matches:")
+		for _, line := range match.Samples {
+			messageLines = append(messageLines, string(truncate(line.Content, maxLineLength)))
+		}
 	}
 	message := strings.Join(messageLines, "\n")
 
 	return message
 }
+
+func matchTitle(severity logfilter.Severity) string {
+	switch severity {
+	case logfilter.SeverityInfo:
+		return "ℹ️ Log event detected"
+	case logfilter.SeverityWarning:
+		return "⚠️ Warning detected!"
+	case logfilter.SeverityCritical:
+		return "🔥 Critical error detected!"
+	default:
+		return "🚨 Error detected!"
+	}
+}
+
+// traceLines returns the continuation lines of a multi-line event, up to
+// maxTraceLength in total.
+func traceLines(trace []byte, omitted int) []string {
+	lines := bytes.Split(trace, []byte{'\n'})
+	res := make([]string, 0, len(lines))
+
+	length := 0
+	for i, line := range lines {
+		line = truncate(line, maxTraceLineLength)
+		if length+len(line) > maxTraceLength {
+			omitted += len(lines) - i
+			break
+		}
+		length += len(line) + 1
+		res = append(res, string(line))
+	}
+
+	if omitted > 0 {
+		res = append(res, fmt.Sprintf("... %d more lines", omitted))
+	}
+
+	return res
+}
+
+// contextLines returns the context of a match, with matching lines marked by
+// ">", up to maxTraceLength in total.
+func contextLines(context []watcher.ContextLine) []string {
+	res := make([]string, 0, len(context))
+
+	length := 0
+	for i, line := range context {
+		prefix := "  "
+		if line.Matched {
+			prefix = "> "
+		}
+
+		text := prefix + string(truncate(line.Line.Content, maxTraceLineLength))
+		if length+len(text) > maxTraceLength {
+			res = append(res, fmt.Sprintf("... %d more lines", len(context)-i))
+			break
+		}
+		length += len(text) + 1
+		res = append(res, text)
+	}
+
+	return res
+}
+
+func PrepareCrashMessage(crash *watcher.Crash) string {
+	messageLines := []string{
+		"💥 Container crashed!",
+	}
+	messageLines = append(messageLines, containerLines(crash.Container)...)
+	messageLines = append(messageLines,
+		fmt.Sprintf("Exit code = %d; OOM killed = %t; Restart count = %d", crash.ExitCode, crash.OOMKilled, crash.RestartCount),
+	)
+
+	if len(crash.Lines) > 0 {
+		messageLines = append(messageLines, "Last lines:")
+		for _, line := range crash.Lines {
+			messageLines = append(messageLines, string(truncate(line.Content, maxLineLength)))
+		}
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+func PrepareCrashLoopMessage(loop *watcher.CrashLoop) string {
+	title := "🔁 Container is crash looping!"
+	if loop.Update {
+		title = "🔁 Container is still crash looping"
+	}
+
+	messageLines := []string{
+		title,
+	}
+	messageLines = append(messageLines, containerLines(loop.Container)...)
+	messageLines = append(messageLines,
+		fmt.Sprintf("Restarts = %d in %s; Restart count = %d", loop.Restarts, loop.Window, loop.RestartCount),
+	)
+
+	if len(loop.Lines) > 0 {
+		messageLines = append(messageLines, "Recent errors:")
+		for _, line := range loop.Lines {
+			messageLines = append(messageLines, string(truncate(line.Content, maxLineLength)))
+		}
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+const maxProbeOutputLength = 300
+
+func PrepareHealthMessage(change *watcher.HealthChange) string {
+	if change.Recovered() {
+		messageLines := []string{"✅ Container is healthy again"}
+		messageLines = append(messageLines, containerLines(change.Container)...)
+		return strings.Join(messageLines, "\n")
+	}
+
+	messageLines := []string{"🩺 Container is unhealthy!"}
+	messageLines = append(messageLines, containerLines(change.Container)...)
+
+	previous := change.Previous
+	if previous == "" {
+		previous = "unknown"
+	}
+	messageLines = append(messageLines,
+		fmt.Sprintf("Previous status = %s; Failing streak = %d", previous, change.FailingStreak),
+	)
+
+	if change.Probe != nil {
+		output := strings.TrimSpace(change.Probe.Output)
+		messageLines = append(messageLines,
+			fmt.Sprintf("Last probe (exit code %d):", change.Probe.ExitCode),
+			string(truncate([]byte(output), maxProbeOutputLength)),
+		)
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+func PrepareHostMessage(status *watcher.HostStatus) string {
+	host := status.Host
+	if host == "" {
+		host = "default"
+	}
+
+	if status.Recovered() {
+		return strings.Join([]string{
+			"✅ Docker host is reachable again",
+			fmt.Sprintf("Host = %s", host),
+			fmt.Sprintf("Unreachable since = %s", status.Since.Format(time.RFC3339)),
+		}, "\n")
+	}
+
+	messageLines := []string{
+		"🔌 Docker host is unreachable!",
+		fmt.Sprintf("Host = %s", host),
+		fmt.Sprintf("Since = %s", status.Since.Format(time.RFC3339)),
+	}
+	if status.Err != nil {
+		messageLines = append(messageLines, fmt.Sprintf("Error: %s", truncate([]byte(status.Err.Error()), maxProbeOutputLength)))
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+func PrepareStatsMessage(breach *stats.Breach) string {
+	title := fmt.Sprintf("📈 High %s usage!", metricName(breach.Metric))
+	if breach.Resolved {
+		title = fmt.Sprintf("✅ %s usage is back to normal", metricName(breach.Metric))
+	}
+
+	messageLines := []string{title}
+	messageLines = append(messageLines, containerLines(breach.Container)...)
+	messageLines = append(messageLines,
+		fmt.Sprintf("Value = %s; Threshold = %s; Since = %s",
+			formatMetric(breach.Metric, breach.Value),
+			formatMetric(breach.Metric, breach.Threshold),
+			breach.Since.Format(time.RFC3339),
+		),
+	)
+
+	if !breach.Resolved {
+		s := breach.Stats
+		messageLines = append(messageLines,
+			fmt.Sprintf("CPU = %.1f%%; Memory = %s / %s; PIDs = %d",
+				s.CPUPercent, formatBytes(s.MemoryUsage), formatBytes(s.MemoryLimit), s.Pids),
+		)
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+func PrepareDiskMessage(breach *stats.DiskBreach) string {
+	title := fmt.Sprintf("💾 Docker %s use too much disk space!", breach.Resource)
+	if breach.Resolved {
+		title = fmt.Sprintf("✅ Docker %s disk usage is back to normal", breach.Resource)
+	}
+
+	messageLines := []string{title}
+	if breach.Host != "" {
+		messageLines = append(messageLines, fmt.Sprintf("Host = %s", breach.Host))
+	}
+	messageLines = append(messageLines,
+		fmt.Sprintf("Size = %s; Threshold = %s", formatBytes(uint64(breach.Size)), formatBytes(uint64(breach.Threshold))),
+	)
+
+	if len(breach.Offenders) > 0 {
+		messageLines = append(messageLines, "Largest:")
+		for _, item := range breach.Offenders {
+			line := fmt.Sprintf("%s: %s", truncate([]byte(item.Name), maxLineLength), formatBytes(uint64(item.Size)))
+			if !item.InUse {
+				line += " (unused)"
+			}
+			messageLines = append(messageLines, line)
+		}
+	}
+
+	return strings.Join(messageLines, "\n")
+}
+
+func metricName(metric stats.Metric) string {
+	switch metric {
+	case stats.MetricCPU:
+		return "CPU"
+	case stats.MetricMemory:
+		return "Memory"
+	case stats.MetricPids:
+		return "PID"
+	}
+	return string(metric)
+}
+
+func formatMetric(metric stats.Metric, value float64) string {
+	if metric == stats.MetricPids {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// containerLines describes where an alert came from: the container, or the
+// Swarm service task, and, when known, its image, compose project and service,
+// and host.
+func containerLines(c container.Container) []string {
+	lines := []string{
+		fmt.Sprintf("Container ID = %s; Container name = %s", c.ID, c.Name),
+	}
+	if c.Service != "" {
+		lines[0] = fmt.Sprintf("Service = %s; Task slot = %d; Task ID = %s", c.Service, c.TaskSlot, c.ID)
+	}
+
+	if c.Image != "" {
+		lines = append(lines, fmt.Sprintf("Image = %s", c.Image))
+	}
+
+	if project := c.ComposeProject(); project != "" {
+		compose := fmt.Sprintf("Compose project = %s", project)
+		if service := c.ComposeService(); service != "" {
+			compose += fmt.Sprintf("; Compose service = %s", service)
+		}
+		lines = append(lines, compose)
+	}
+
+	if c.Host != "" {
+		lines = append(lines, fmt.Sprintf("Host = %s", c.Host))
+	}
+
+	return lines
+}
+
+// truncate cuts line to at most limit bytes, stepping back to the start of
+// a rune so that no multi-byte character is split.
+func truncate(line []byte, limit int) []byte {
+	if len(line) <= limit {
+		return line
+	}
+	for limit > 0 && !utf8.RuneStart(line[limit]) {
+		limit--
+	}
+	return line[:limit]
+}
@@ -1,11 +1,15 @@
 package alerts_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/alerts"
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
 	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/stats"
 	"github.com/andvarfolomeev/docker-notifier/internal/watcher"
 )
 
@@ -41,6 +45,183 @@ func TestPrepareMessage(t *testing.T) {
 			},
 			expected: "🚨 Error detected!\nContainer ID = def456; Container name = long-error-container\nLine: \"Error: very long error message that exceeds 100 characters and should be truncated by the formatting\"",
 		},
+		{
+			name: "long non-ASCII error message is cut between characters",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "def456",
+					Name: "long-error-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte(strings.Repeat("ошибка ", 20)),
+				},
+			},
+			expected: "🚨 Error detected!\nContainer ID = def456; Container name = long-error-container\nLine: \"" + strings.Repeat("ошибка ", 7) + "ошиб\"",
+		},
+		{
+			name: "multi-line error message includes the trace",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("java.lang.IllegalStateException: boom\n\tat com.example.App.run(App.java:10)\n\tat com.example.App.main(App.java:5)"),
+					Omitted: 3,
+				},
+			},
+			expected: "🚨 Error detected!\nContainer ID = abc123; Container name = test-container\nLine: \"java.lang.IllegalStateException: boom\"\nTrace:\n\tat com.example.App.run(App.java:10)\n\tat com.example.App.main(App.java:5)\n... 3 more lines",
+		},
+		{
+			name: "structured error message shows the extracted fields",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte(`{"level":"error","msg":"payment failed","trace_id":"4bf92f35","error":"card declined"}`),
+					Fields: []logfilter.Field{
+						{Key: "msg", Value: "payment failed"},
+						{Key: "trace_id", Value: "4bf92f35"},
+						{Key: "error", Value: "card declined"},
+					},
+				},
+			},
+			expected: "🚨 Error detected!\nContainer ID = abc123; Container name = test-container\nmsg: \"payment failed\"\ntrace_id: \"4bf92f35\"\nerror: \"card declined\"",
+		},
+		{
+			name: "critical rule with a description",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("ORA-00060: deadlock detected"),
+				},
+				Rule: &logfilter.Rule{
+					Name:        "db",
+					Severity:    logfilter.SeverityCritical,
+					Description: "Database errors page the on-call",
+				},
+			},
+			expected: "🔥 Critical error detected!\nContainer ID = abc123; Container name = test-container\nRule = db; Severity = critical\nDatabase errors page the on-call\nLine: \"ORA-00060: deadlock detected\"",
+		},
+		{
+			name: "warning rule",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("WARN slow query"),
+				},
+				Rule: &logfilter.Rule{
+					Name:     "slow",
+					Severity: logfilter.SeverityWarning,
+				},
+			},
+			expected: "⚠️ Warning detected!\nContainer ID = abc123; Container name = test-container\nRule = slow; Severity = warning\nLine: \"WARN slow query\"",
+		},
+		{
+			name: "stderr rule",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("DeprecationWarning: use run()"),
+					Stream:  logfilter.StreamStderr,
+				},
+				Rule: &logfilter.Rule{
+					Name:     "stderr",
+					Severity: logfilter.SeverityWarning,
+					Stream:   logfilter.StreamStderr,
+				},
+			},
+			expected: "⚠️ Warning detected!\nContainer ID = abc123; Container name = test-container\nRule = stderr; Severity = warning\nStream = stderr\nLine: \"DeprecationWarning: use run()\"",
+		},
+		{
+			name: "threshold rule",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("connection reset by peer (5)"),
+				},
+				Rule: &logfilter.Rule{
+					Name:      "resets",
+					Severity:  logfilter.SeverityWarning,
+					Threshold: 100,
+					Window:    5 * time.Minute,
+				},
+				Count: 100,
+				Samples: []*logfilter.MatchedLine{
+					{Content: []byte("connection reset by peer (3)")},
+					{Content: []byte("connection reset by peer (4)")},
+				},
+			},
+			expected: "⚠️ Warning detected!\nContainer ID = abc123; Container name = test-container\nRule = resets; Severity = warning\nMatches = 100 in 5m0s\nLine: \"connection reset by peer (5)\"\nEarlier matches:\nconnection reset by peer (3)\nconnection reset by peer (4)",
+		},
+		{
+			name: "error message with context lines",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:   "abc123",
+					Name: "test-container",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("ERROR payment failed"),
+				},
+				Context: []watcher.ContextLine{
+					{Line: &logfilter.MatchedLine{Content: []byte("charging card")}},
+					{Line: &logfilter.MatchedLine{Content: []byte("ERROR payment failed")}, Matched: true},
+					{Line: &logfilter.MatchedLine{Content: []byte("rolling back")}},
+				},
+			},
+			expected: "🚨 Error detected!\nContainer ID = abc123; Container name = test-container\nLine: \"ERROR payment failed\"\nContext:\n  charging card\n> ERROR payment failed\n  rolling back",
+		},
+		{
+			name: "error message of a swarm service task",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:       "task1",
+					Name:     "shop_web.2",
+					Image:    "shop/web:1.2",
+					Host:     "manager-1",
+					Service:  "shop_web",
+					TaskSlot: 2,
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("ERROR payment failed"),
+				},
+			},
+			expected: "🚨 Error detected!\nService = shop_web; Task slot = 2; Task ID = task1\nImage = shop/web:1.2\nHost = manager-1\nLine: \"ERROR payment failed\"",
+		},
+		{
+			name: "error message with container metadata",
+			match: &watcher.MatchedLog{
+				Container: container.Container{
+					ID:    "jkl012",
+					Name:  "shop-web-1",
+					Image: "shop/web:1.2",
+					Labels: map[string]string{
+						container.LabelComposeProject: "shop",
+						container.LabelComposeService: "web",
+					},
+					Host: "docker-host-1",
+				},
+				Line: &logfilter.MatchedLine{
+					Content: []byte("Error: connection refused"),
+				},
+			},
+			expected: "🚨 Error detected!\nContainer ID = jkl012; Container name = shop-web-1\nImage = shop/web:1.2\nCompose project = shop; Compose service = web\nHost = docker-host-1\nLine: \"Error: connection refused\"",
+		},
 		{
 			name: "empty error message",
 			match: &watcher.MatchedLog{
@@ -65,3 +246,260 @@ func TestPrepareMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestPrepareCrashMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		crash    *watcher.Crash
+		expected string
+	}{
+		{
+			name: "crash with last lines",
+			crash: &watcher.Crash{
+				Container:    container.Container{ID: "abc123", Name: "test-container"},
+				ExitCode:     137,
+				OOMKilled:    true,
+				RestartCount: 2,
+				Lines: []*logfilter.MatchedLine{
+					{Content: []byte("allocating buffer")},
+					{Content: []byte("fatal: out of memory")},
+				},
+			},
+			expected: "💥 Container crashed!\nContainer ID = abc123; Container name = test-container\nExit code = 137; OOM killed = true; Restart count = 2\nLast lines:\nallocating buffer\nfatal: out of memory",
+		},
+		{
+			name: "crash without logs",
+			crash: &watcher.Crash{
+				Container: container.Container{ID: "def456", Name: "quiet-container"},
+				ExitCode:  1,
+			},
+			expected: "💥 Container crashed!\nContainer ID = def456; Container name = quiet-container\nExit code = 1; OOM killed = false; Restart count = 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareCrashMessage(tc.crash)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
+
+func TestPrepareCrashLoopMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		loop     *watcher.CrashLoop
+		expected string
+	}{
+		{
+			name: "first alert",
+			loop: &watcher.CrashLoop{
+				Container:    container.Container{ID: "abc123", Name: "test-container", Image: "app:latest"},
+				Restarts:     5,
+				Window:       10 * time.Minute,
+				RestartCount: 42,
+				Lines: []*logfilter.MatchedLine{
+					{Content: []byte("ERROR: cannot connect to database")},
+				},
+			},
+			expected: "🔁 Container is crash looping!\nContainer ID = abc123; Container name = test-container\nImage = app:latest\nRestarts = 5 in 10m0s; Restart count = 42\nRecent errors:\nERROR: cannot connect to database",
+		},
+		{
+			name: "update",
+			loop: &watcher.CrashLoop{
+				Container:    container.Container{ID: "abc123", Name: "test-container", Image: "app:latest"},
+				Restarts:     12,
+				Window:       10 * time.Minute,
+				RestartCount: 90,
+				Update:       true,
+			},
+			expected: "🔁 Container is still crash looping\nContainer ID = abc123; Container name = test-container\nImage = app:latest\nRestarts = 12 in 10m0s; Restart count = 90",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareCrashLoopMessage(tc.loop)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
+
+func TestPrepareHealthMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		change   *watcher.HealthChange
+		expected string
+	}{
+		{
+			name: "unhealthy with failing probe",
+			change: &watcher.HealthChange{
+				Container:     container.Container{ID: "abc123", Name: "test-container"},
+				Status:        "unhealthy",
+				Previous:      "healthy",
+				FailingStreak: 3,
+				Probe:         &container.HealthProbe{ExitCode: 1, Output: "curl: (7) Failed to connect\n"},
+			},
+			expected: "🩺 Container is unhealthy!\nContainer ID = abc123; Container name = test-container\nPrevious status = healthy; Failing streak = 3\nLast probe (exit code 1):\ncurl: (7) Failed to connect",
+		},
+		{
+			name: "unhealthy with unknown previous status",
+			change: &watcher.HealthChange{
+				Container: container.Container{ID: "abc123", Name: "test-container"},
+				Status:    "unhealthy",
+			},
+			expected: "🩺 Container is unhealthy!\nContainer ID = abc123; Container name = test-container\nPrevious status = unknown; Failing streak = 0",
+		},
+		{
+			name: "recovered",
+			change: &watcher.HealthChange{
+				Container: container.Container{ID: "abc123", Name: "test-container"},
+				Status:    "healthy",
+				Previous:  "unhealthy",
+			},
+			expected: "✅ Container is healthy again\nContainer ID = abc123; Container name = test-container",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareHealthMessage(tc.change)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
+
+func TestPrepareStatsMessage(t *testing.T) {
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		breach   *stats.Breach
+		expected string
+	}{
+		{
+			name: "memory breach",
+			breach: &stats.Breach{
+				Container: container.Container{ID: "abc123", Name: "test-container"},
+				Metric:    stats.MetricMemory,
+				Value:     93.75,
+				Threshold: 90,
+				Since:     since,
+				Stats: container.Stats{
+					CPUPercent:    12.5,
+					MemoryUsage:   480 * 1024 * 1024,
+					MemoryLimit:   512 * 1024 * 1024,
+					MemoryPercent: 93.75,
+					Pids:          42,
+				},
+			},
+			expected: "📈 High Memory usage!\nContainer ID = abc123; Container name = test-container\nValue = 93.8%; Threshold = 90.0%; Since = 2024-01-01T12:00:00Z\nCPU = 12.5%; Memory = 480.0 MiB / 512.0 MiB; PIDs = 42",
+		},
+		{
+			name: "resolved pids breach",
+			breach: &stats.Breach{
+				Container: container.Container{ID: "abc123", Name: "test-container"},
+				Metric:    stats.MetricPids,
+				Value:     20,
+				Threshold: 100,
+				Since:     since,
+				Resolved:  true,
+			},
+			expected: "✅ PID usage is back to normal\nContainer ID = abc123; Container name = test-container\nValue = 20; Threshold = 100; Since = 2024-01-01T12:00:00Z",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareStatsMessage(tc.breach)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
+
+func TestPrepareDiskMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		breach   *stats.DiskBreach
+		expected string
+	}{
+		{
+			name: "images breach",
+			breach: &stats.DiskBreach{
+				Host:      "docker-host-1",
+				Resource:  stats.DiskImages,
+				Size:      12 * 1024 * 1024 * 1024,
+				Threshold: 10 * 1024 * 1024 * 1024,
+				Offenders: []container.DiskUsageItem{
+					{Name: "app:latest", Size: 3 * 1024 * 1024 * 1024, InUse: true},
+					{Name: "0123456789ab", Size: 512 * 1024 * 1024},
+				},
+			},
+			expected: "💾 Docker images use too much disk space!\nHost = docker-host-1\nSize = 12.0 GiB; Threshold = 10.0 GiB\nLargest:\napp:latest: 3.0 GiB\n0123456789ab: 512.0 MiB (unused)",
+		},
+		{
+			name: "resolved build cache breach",
+			breach: &stats.DiskBreach{
+				Resource:  stats.DiskBuildCache,
+				Size:      100,
+				Threshold: 2048,
+				Resolved:  true,
+			},
+			expected: "✅ Docker build cache disk usage is back to normal\nSize = 100 B; Threshold = 2.0 KiB",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareDiskMessage(tc.breach)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
+
+func TestPrepareHostMessage(t *testing.T) {
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		status   *watcher.HostStatus
+		expected string
+	}{
+		{
+			name: "lost",
+			status: &watcher.HostStatus{
+				Host:  "docker-host-1",
+				Lost:  true,
+				Since: since,
+				Err:   errors.New("dial tcp 10.0.0.5:2376: connect: connection refused"),
+			},
+			expected: "🔌 Docker host is unreachable!\nHost = docker-host-1\nSince = 2024-01-01T12:00:00Z\nError: dial tcp 10.0.0.5:2376: connect: connection refused",
+		},
+		{
+			name: "recovered default host",
+			status: &watcher.HostStatus{
+				Since: since,
+			},
+			expected: "✅ Docker host is reachable again\nHost = default\nUnreachable since = 2024-01-01T12:00:00Z",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			message := alerts.PrepareHostMessage(tc.status)
+			if message != tc.expected {
+				t.Errorf("expected message: %q, got: %q", tc.expected, message)
+			}
+		})
+	}
+}
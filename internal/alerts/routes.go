@@ -0,0 +1,22 @@
+package alerts
+
+import "github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+
+// Routes decide where error alerts go by the severity of the rule that
+// fired.
+type Routes struct {
+	// MinSeverity drops alerts of less severe rules.
+	MinSeverity logfilter.Severity
+	// Chats sends alerts of a severity to another Telegram chat than the
+	// default one.
+	Chats map[logfilter.Severity]string
+}
+
+// Route returns the chat for an alert of the severity, empty for the default
+// chat, and false when the alert is dropped.
+func (r Routes) Route(severity logfilter.Severity) (string, bool) {
+	if severity < r.MinSeverity {
+		return "", false
+	}
+	return r.Chats[severity], true
+}
@@ -0,0 +1,42 @@
+package alerts_test
+
+import (
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/alerts"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestRoutes_Route(t *testing.T) {
+	routes := alerts.Routes{
+		MinSeverity: logfilter.SeverityWarning,
+		Chats:       map[logfilter.Severity]string{logfilter.SeverityCritical: "-100123"},
+	}
+
+	tests := []struct {
+		severity   logfilter.Severity
+		wantChatID string
+		wantOK     bool
+	}{
+		{severity: logfilter.SeverityInfo, wantOK: false},
+		{severity: logfilter.SeverityWarning, wantChatID: "", wantOK: true},
+		{severity: logfilter.SeverityError, wantChatID: "", wantOK: true},
+		{severity: logfilter.SeverityCritical, wantChatID: "-100123", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			chatID, ok := routes.Route(tt.severity)
+			if ok != tt.wantOK {
+				t.Errorf("Route() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if chatID != tt.wantChatID {
+				t.Errorf("Route() chatID = %q, want %q", chatID, tt.wantChatID)
+			}
+		})
+	}
+
+	if _, ok := (alerts.Routes{}).Route(logfilter.SeverityInfo); !ok {
+		t.Error("expected every severity to pass without a minimum")
+	}
+}
@@ -1,19 +1,97 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/redact"
 	"github.com/spf13/pflag"
 )
 
+// Endpoint is a named Docker daemon to monitor.
+type Endpoint struct {
+	Name     string
+	Host     string
+	CertPath string
+}
+
 type Config struct {
-	Interval       int
-	LabelEnable    bool
-	TelegramToken  string
-	TelegramChatID string
-	ErrorPatterns  []string
-	Debug          bool
+	DockerHost  string
+	TLSVerify   bool
+	TLSCertPath string
+	Endpoints   []Endpoint
+	HostAlerts  bool
+
+	SwarmServices bool
+
+	Interval        int
+	LabelEnable     bool
+	HealthcheckOnly bool
+	TelegramToken   string
+	TelegramChatID  string
+	ErrorPatterns   []string
+	Follow          bool
+
+	// MaxLineLength cuts longer log lines before matching.
+	MaxLineLength int
+	Sanitize      bool
+	// PollMaxLines and PollMaxBytes limit the log output read from a
+	// container in one poll.
+	PollMaxLines int
+	PollMaxBytes int64
+
+	ContextBefore   int
+	ContextAfter    int
+	Rules           []logfilter.RuleConfig
+	ExcludePatterns []string
+	RuleExcludes    map[string][]string
+	MinSeverity     logfilter.Severity
+	SeverityChats   map[logfilter.Severity]string
+	// TestLine is a log line to evaluate against the rules instead of
+	// monitoring, as if written to TestStream.
+	TestLine   string
+	TestStream logfilter.Stream
+
+	MultilinePreset       string
+	MultilineStart        string
+	MultilineContinuation string
+	MultilineMaxLines     int
+
+	LogFormat   string
+	FieldRules  []string
+	AlertFields []string
+
+	Redact         []string
+	RedactPatterns []string
+
+	CrashAlerts   bool
+	CrashLogLines int
+
+	CrashLoopThreshold      int
+	CrashLoopWindow         time.Duration
+	CrashLoopUpdateInterval time.Duration
+
+	HealthAlerts bool
+
+	StatsInterval     time.Duration
+	CPUThreshold      float64
+	MemoryThreshold   float64
+	PidsThreshold     uint64
+	ThresholdDuration time.Duration
+
+	DiskInterval        time.Duration
+	ImagesThreshold     int64
+	VolumesThreshold    int64
+	BuildCacheThreshold int64
+
+	Debug bool
 }
 
 func Usage() {
@@ -25,15 +103,68 @@ func Usage() {
 }
 
 func Parse() (*Config, error) {
+	dockerHost := pflag.String("docker-host", envOr("DOCKER_HOST", docker.DetectHost()), "Docker daemon address, unix:// or tcp:// (env DOCKER_HOST, defaults to the Docker or Podman socket found)")
+	tlsVerify := pflag.Bool("tls-verify", os.Getenv("DOCKER_TLS_VERIFY") != "", "Use mutual TLS to connect to a tcp:// daemon (env DOCKER_TLS_VERIFY)")
+	tlsCertPath := pflag.String("tls-cert-path", envOr("DOCKER_CERT_PATH", defaultCertPath()), "Directory with ca.pem, cert.pem and key.pem (env DOCKER_CERT_PATH)")
+	hostAlerts := pflag.Bool("host-alerts", true, "Alert when a Docker host stops answering and when it is back")
+	var endpoints []string
+	pflag.StringArrayVar(&endpoints, "endpoint", nil, "Named Docker daemon to monitor as name=address, e.g. edge-1=tcp://10.0.0.5:2376 (can be used multiple times, overrides --docker-host)")
+	swarmServices := pflag.Bool("swarm-services", false, "Stream the logs of Swarm services and name the service and task slot in alerts (requires a manager node)")
 	interval := pflag.Int("interval", 5, "Log polling interval in seconds")
 	labelEnable := pflag.Bool("label-enable", false, "Enable label filter: com.andvarfolomeev.dockernotifier.enable=true")
+	healthcheckOnly := pflag.Bool("only-healthchecked", false, "Ignore containers without a healthcheck entirely: their logs, exits and stats are not monitored either")
 	telegramToken := pflag.String("telegram-token", "", "Telegram Bot API token")
 	telegramChatID := pflag.String("telegram-chat-id", "", "Target chat ID")
+	follow := pflag.Bool("follow", false, "Stream logs over one long-lived connection per container instead of polling")
+	maxLineLength, pollMaxBytes := byteSize(64<<10), byteSize(16<<20)
+	pflag.Var(&maxLineLength, "max-line-length", "Longer log lines are cut to this size before matching, e.g. 64KiB (0 disables)")
+	sanitize := pflag.Bool("sanitize", true, "Strip ANSI escape sequences and control characters from log lines before matching, and fix invalid UTF-8")
+	pollMaxLines := pflag.Int("poll-max-lines", 50000, "Log lines read from a container per poll, the rest of its output is skipped (0 disables)")
+	pflag.Var(&pollMaxBytes, "poll-max-bytes", "Log output read from a container per poll, e.g. 16MiB, the rest of it is skipped (0 disables)")
+	multilinePreset := pflag.String("multiline", "", "Group stack traces into one alert using a preset: java, python, go or node")
+	multilineStart := pflag.String("multiline-start", "", "Regex for the first line of a multi-line event, other lines continue it (overrides the preset)")
+	multilineContinuation := pflag.String("multiline-continuation", "", "Regex for lines that continue a multi-line event (overrides the preset)")
+	multilineMaxLines := pflag.Int("multiline-max-lines", logfilter.DefaultMultilineMaxLines, "Maximum number of lines kept per multi-line event")
+	logFormat := pflag.String("log-format", "text", "Format of container log lines: text, json or logfmt (the com.andvarfolomeev.dockernotifier.log-format label overrides it per container)")
+	var fieldRules, alertFields []string
+	pflag.StringArrayVar(&fieldRules, "field-rule", nil, "Rule on a field of JSON and logfmt lines, e.g. 'level in (error, fatal)' or 'status >= 500' (can be used multiple times, replaces --error-pattern for those lines)")
+	var redactDetectors, redactPatterns []string
+	pflag.StringSliceVar(&redactDetectors, "redact", redact.Detectors(), "Built-in detectors of secrets and personal data masked in alerts (empty disables)")
+	pflag.StringArrayVar(&redactPatterns, "redact-pattern", nil, "Regex pattern masked in alerts, only its group named secret when it has one (can be used multiple times)")
+	pflag.StringSliceVar(&alertFields, "alert-field", []string{"msg", "message", "trace_id", "error", "err"}, "Fields of JSON and logfmt lines shown in alerts in place of the raw line")
+	crashAlerts := pflag.Bool("crash-alerts", true, "Alert when a container exits with a non-zero code or is OOM-killed")
+	crashLogLines := pflag.Int("crash-log-lines", 10, "Number of last log lines included in crash alerts")
+	crashLoopThreshold := pflag.Int("crash-loop-threshold", 0, "Restarts within --crash-loop-window that trigger a crash loop alert, e.g. 5 (0 disables)")
+	crashLoopWindow := pflag.Duration("crash-loop-window", 10*time.Minute, "Time window for counting restarts")
+	crashLoopUpdateInterval := pflag.Duration("crash-loop-update-interval", 30*time.Minute, "Minimum time between updates while a container keeps crash looping")
+	healthAlerts := pflag.Bool("health-alerts", false, "Alert when a container turns unhealthy and when it recovers")
+	statsInterval := pflag.Duration("stats-interval", 30*time.Second, "Resource usage sampling interval (0 disables usage alerts)")
+	cpuThreshold := pflag.Float64("cpu-threshold", 0, "CPU usage in percent of one CPU that triggers an alert (0 disables)")
+	memoryThreshold := pflag.Float64("memory-threshold", 0, "Memory usage in percent of the container limit that triggers an alert (0 disables)")
+	pidsThreshold := pflag.Uint64("pids-threshold", 0, "Number of processes that triggers an alert (0 disables)")
+	thresholdDuration := pflag.Duration("threshold-duration", 5*time.Minute, "How long usage must stay above a threshold before alerting")
+	diskInterval := pflag.Duration("disk-interval", 10*time.Minute, "Disk usage check interval (0 disables disk usage alerts)")
+	var imagesThreshold, volumesThreshold, buildCacheThreshold byteSize
+	pflag.Var(&imagesThreshold, "images-threshold", "Total image size that triggers an alert, e.g. 20GB (0 disables)")
+	pflag.Var(&volumesThreshold, "volumes-threshold", "Total volume size that triggers an alert, e.g. 50GB (0 disables)")
+	pflag.Var(&buildCacheThreshold, "build-cache-threshold", "Total build cache size that triggers an alert, e.g. 10GB (0 disables)")
 	debug := pflag.Bool("debug", false, "Enable debug logging")
 
 	var errorPatterns []string
 	pflag.StringSliceVar(&errorPatterns, "error-pattern", []string{"ERROR"}, "Regex pattern for matching error lines (can be used multiple times)")
 
+	contextBefore := pflag.Int("context-before", 0, "Number of log lines before a match of --error-pattern included in the alert")
+	contextAfter := pflag.Int("context-after", 0, "Number of log lines after a match of --error-pattern included in the alert")
+	rulesFile := pflag.String("rules-file", "", "JSON file with named rules: name, patterns, exclude, case_sensitive, severity, description, context_before, context_after, stream, threshold, window and endpoints")
+	minSeverity := pflag.String("min-severity", "info", "Least severe rule that triggers an alert: info, warning, error or critical")
+	var severityChats map[string]string
+	pflag.StringToStringVar(&severityChats, "severity-chat", nil, "Telegram chat ID per severity, e.g. critical=-100123 (defaults to --telegram-chat-id)")
+	var excludePatterns, ruleExcludes []string
+	pflag.StringArrayVar(&excludePatterns, "exclude-pattern", nil, "Regex pattern that suppresses matches of every error pattern (can be used multiple times)")
+	pflag.StringArrayVar(&ruleExcludes, "rule-exclude", nil, "Regex pattern that suppresses matches of one rule or error pattern, as name=exclude, e.g. 'ERROR=0 rows affected' (can be used multiple times)")
+	testLine := pflag.String("test-line", "", "Print which rules include or exclude the given log line, and exit")
+	testStream := pflag.String("test-stream", "", "Stream the --test-line was written to: stdout, stderr or tty (defaults to any)")
+
 	help := pflag.BoolP("help", "h", false, "Display help information")
 
 	pflag.Usage = Usage
@@ -45,6 +176,69 @@ func Parse() (*Config, error) {
 		return nil, ErrHelpRequested
 	}
 
+	var (
+		rules []logfilter.RuleConfig
+		err   error
+	)
+	if *rulesFile != "" {
+		rules, err = readRules(*rulesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// The default pattern only applies without named rules.
+		if !pflag.CommandLine.Changed("error-pattern") {
+			errorPatterns = nil
+		}
+	}
+
+	ruleNames := slices.Clone(errorPatterns)
+	for _, rule := range rules {
+		ruleNames = append(ruleNames, rule.Name)
+	}
+
+	parsedRuleExcludes, err := parseRuleExcludes(ruleExcludes, ruleNames)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedMinSeverity, err := logfilter.ParseSeverity(*minSeverity)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedSeverityChats := make(map[logfilter.Severity]string, len(severityChats))
+	for name, chatID := range severityChats {
+		severity, err := logfilter.ParseSeverity(name)
+		if err != nil {
+			return nil, err
+		}
+		parsedSeverityChats[severity] = chatID
+	}
+
+	if *testLine != "" {
+		var parsedTestStream logfilter.Stream
+		if *testStream != "" {
+			parsedTestStream, err = logfilter.ParseStream(*testStream)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &Config{
+			ErrorPatterns:   errorPatterns,
+			Rules:           rules,
+			ExcludePatterns: excludePatterns,
+			RuleExcludes:    parsedRuleExcludes,
+			TestLine:        *testLine,
+			TestStream:      parsedTestStream,
+			Sanitize:        *sanitize,
+			LogFormat:       *logFormat,
+			FieldRules:      fieldRules,
+			AlertFields:     alertFields,
+		}, nil
+	}
+
 	if *telegramToken == "" {
 		return nil, ErrMissingArg("--telegram-token")
 	}
@@ -53,14 +247,185 @@ func Parse() (*Config, error) {
 		return nil, ErrMissingArg("--telegram-chat")
 	}
 
+	parsedEndpoints, err := parseEndpoints(endpoints, *dockerHost, *tlsCertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRuleEndpoints(rules, parsedEndpoints); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		Interval:       *interval,
-		LabelEnable:    *labelEnable,
-		TelegramToken:  *telegramToken,
-		TelegramChatID: *telegramChatID,
-		ErrorPatterns:  errorPatterns,
-		Debug:          *debug,
+		DockerHost:  *dockerHost,
+		TLSVerify:   *tlsVerify,
+		TLSCertPath: *tlsCertPath,
+		Endpoints:   parsedEndpoints,
+		HostAlerts:  *hostAlerts,
+
+		SwarmServices: *swarmServices,
+
+		Interval:        *interval,
+		LabelEnable:     *labelEnable,
+		HealthcheckOnly: *healthcheckOnly,
+		TelegramToken:   *telegramToken,
+		TelegramChatID:  *telegramChatID,
+		ErrorPatterns:   errorPatterns,
+		Follow:          *follow,
+
+		MaxLineLength: int(maxLineLength),
+		Sanitize:      *sanitize,
+		PollMaxLines:  *pollMaxLines,
+		PollMaxBytes:  int64(pollMaxBytes),
+
+		ContextBefore:   *contextBefore,
+		ContextAfter:    *contextAfter,
+		Rules:           rules,
+		ExcludePatterns: excludePatterns,
+		RuleExcludes:    parsedRuleExcludes,
+		MinSeverity:     parsedMinSeverity,
+		SeverityChats:   parsedSeverityChats,
+
+		MultilinePreset:       *multilinePreset,
+		MultilineStart:        *multilineStart,
+		MultilineContinuation: *multilineContinuation,
+		MultilineMaxLines:     *multilineMaxLines,
+
+		LogFormat:   *logFormat,
+		FieldRules:  fieldRules,
+		AlertFields: alertFields,
+
+		Redact:         redactDetectors,
+		RedactPatterns: redactPatterns,
+
+		CrashAlerts:   *crashAlerts,
+		CrashLogLines: *crashLogLines,
+
+		CrashLoopThreshold:      *crashLoopThreshold,
+		CrashLoopWindow:         *crashLoopWindow,
+		CrashLoopUpdateInterval: *crashLoopUpdateInterval,
+
+		HealthAlerts: *healthAlerts,
+
+		StatsInterval:     *statsInterval,
+		CPUThreshold:      *cpuThreshold,
+		MemoryThreshold:   *memoryThreshold,
+		PidsThreshold:     *pidsThreshold,
+		ThresholdDuration: *thresholdDuration,
+
+		DiskInterval:        *diskInterval,
+		ImagesThreshold:     int64(imagesThreshold),
+		VolumesThreshold:    int64(volumesThreshold),
+		BuildCacheThreshold: int64(buildCacheThreshold),
+
+		Debug: *debug,
 	}
 
 	return config, nil
 }
+
+// readRules reads a JSON array of rule definitions.
+func readRules(path string) ([]logfilter.RuleConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+
+	var rules []logfilter.RuleConfig
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// parseRuleExcludes parses name=exclude pairs. The name is the longest rule
+// name the value starts with, as names of error pattern rules may contain
+// '=' too.
+func parseRuleExcludes(values, ruleNames []string) (map[string][]string, error) {
+	excludes := make(map[string][]string)
+
+	for _, value := range values {
+		name := ""
+		for _, n := range ruleNames {
+			if strings.HasPrefix(value, n+"=") && len(n) > len(name) {
+				name = n
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("invalid rule exclude %q, expected <rule name>=<exclude pattern>", value)
+		}
+
+		excludes[name] = append(excludes[name], strings.TrimPrefix(value, name+"="))
+	}
+
+	return excludes, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func defaultCertPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// parseEndpoints parses name=address pairs. Without any, the single unnamed
+// endpoint at dockerHost is monitored. A named endpoint uses the certificates
+// in the certPath/name directory when it exists, and those in certPath
+// otherwise.
+func parseEndpoints(values []string, dockerHost, certPath string) ([]Endpoint, error) {
+	if len(values) == 0 {
+		return []Endpoint{{Host: dockerHost, CertPath: certPath}}, nil
+	}
+
+	endpoints := make([]Endpoint, 0, len(values))
+	seen := make(map[string]bool, len(values))
+
+	for _, value := range values {
+		name, host, ok := strings.Cut(value, "=")
+		name, host = strings.TrimSpace(name), strings.TrimSpace(host)
+		if !ok || name == "" || host == "" {
+			return nil, fmt.Errorf("invalid endpoint %q, expected name=address", value)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate endpoint name %q", name)
+		}
+		seen[name] = true
+
+		endpoint := Endpoint{Name: name, Host: host, CertPath: certPath}
+		if dir := filepath.Join(certPath, name); certPath != "" {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				endpoint.CertPath = dir
+			}
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+// checkRuleEndpoints fails on rules restricted to endpoints that are not
+// monitored, which would otherwise never match.
+func checkRuleEndpoints(rules []logfilter.RuleConfig, endpoints []Endpoint) error {
+	for _, rule := range rules {
+		for _, name := range rule.Endpoints {
+			if !slices.ContainsFunc(endpoints, func(e Endpoint) bool { return e.Name == name }) {
+				return fmt.Errorf("rule %q names unknown endpoint %q", rule.Name, name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestParseRuleExcludes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		values    []string
+		ruleNames []string
+		expected  map[string][]string
+		wantErr   bool
+	}{
+		{
+			name:      "single rule",
+			values:    []string{"db=connection reset", "db=timeout"},
+			ruleNames: []string{"db", "http"},
+			expected:  map[string][]string{"db": {"connection reset", "timeout"}},
+		},
+		{
+			name:      "exclude containing '='",
+			values:    []string{"http=status=404"},
+			ruleNames: []string{"http"},
+			expected:  map[string][]string{"http": {"status=404"}},
+		},
+		{
+			name:      "longest matching rule name",
+			values:    []string{"level=error=level=error debug"},
+			ruleNames: []string{"level", "level=error"},
+			expected:  map[string][]string{"level=error": {"level=error debug"}},
+		},
+		{
+			name:      "shorter rule name when the longer one does not match",
+			values:    []string{"level=warn"},
+			ruleNames: []string{"level", "level=error"},
+			expected:  map[string][]string{"level": {"warn"}},
+		},
+		{
+			name:      "unknown rule",
+			values:    []string{"cache=miss"},
+			ruleNames: []string{"db"},
+			wantErr:   true,
+		},
+		{
+			name:      "rule name without exclude separator",
+			values:    []string{"db"},
+			ruleNames: []string{"db"},
+			wantErr:   true,
+		},
+		{
+			name:    "no rules",
+			values:  []string{"db=timeout"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			excludes, err := parseRuleExcludes(tc.values, tc.ruleNames)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", excludes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(excludes, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, excludes)
+			}
+		})
+	}
+}
+
+func TestParseEndpoints(t *testing.T) {
+	certPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(certPath, "edge-1"), 0o755); err != nil {
+		t.Fatalf("failed to create cert directory: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		values   []string
+		expected []Endpoint
+		wantErr  bool
+	}{
+		{
+			name:     "no endpoints",
+			expected: []Endpoint{{Host: "unix:///var/run/docker.sock", CertPath: certPath}},
+		},
+		{
+			name:   "named endpoints",
+			values: []string{"edge-1=tcp://10.0.0.5:2376", " edge-2 = tcp://10.0.0.6:2376 "},
+			expected: []Endpoint{
+				{Name: "edge-1", Host: "tcp://10.0.0.5:2376", CertPath: filepath.Join(certPath, "edge-1")},
+				{Name: "edge-2", Host: "tcp://10.0.0.6:2376", CertPath: certPath},
+			},
+		},
+		{
+			name:    "duplicate name",
+			values:  []string{"edge-1=tcp://10.0.0.5:2376", "edge-1=tcp://10.0.0.6:2376"},
+			wantErr: true,
+		},
+		{
+			name:    "missing address",
+			values:  []string{"edge-1="},
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			values:  []string{"=tcp://10.0.0.5:2376"},
+			wantErr: true,
+		},
+		{
+			name:    "no separator",
+			values:  []string{"tcp://10.0.0.5:2376"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints, err := parseEndpoints(tc.values, "unix:///var/run/docker.sock", certPath)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", endpoints)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(endpoints, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, endpoints)
+			}
+		})
+	}
+}
+
+func TestCheckRuleEndpoints(t *testing.T) {
+	endpoints := []Endpoint{
+		{Name: "edge-1", Host: "tcp://10.0.0.5:2376"},
+		{Name: "edge-2", Host: "tcp://10.0.0.6:2376"},
+	}
+
+	testCases := []struct {
+		name      string
+		rules     []logfilter.RuleConfig
+		endpoints []Endpoint
+		wantErr   bool
+	}{
+		{
+			name:      "rule without endpoints",
+			rules:     []logfilter.RuleConfig{{Name: "db"}},
+			endpoints: endpoints,
+		},
+		{
+			name:      "known endpoints",
+			rules:     []logfilter.RuleConfig{{Name: "db", Endpoints: []string{"edge-1", "edge-2"}}},
+			endpoints: endpoints,
+		},
+		{
+			name: "unknown endpoint",
+			rules: []logfilter.RuleConfig{
+				{Name: "db", Endpoints: []string{"edge-1"}},
+				{Name: "http", Endpoints: []string{"edge-3"}},
+			},
+			endpoints: endpoints,
+			wantErr:   true,
+		},
+		{
+			name:      "unnamed default endpoint",
+			rules:     []logfilter.RuleConfig{{Name: "db", Endpoints: []string{"edge-1"}}},
+			endpoints: []Endpoint{{Host: "unix:///var/run/docker.sock"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRuleEndpoints(tc.rules, tc.endpoints)
+			if tc.wantErr && err == nil {
+				t.Error("expected an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestByteSize_Set(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected byteSize
+		wantErr  string
+	}{
+		{name: "plain bytes", value: "512", expected: 512},
+		{name: "bytes suffix", value: "512B", expected: 512},
+		{name: "decimal units", value: "20GB", expected: 20_000_000_000},
+		{name: "binary units", value: "64KiB", expected: 64 << 10},
+		{name: "fraction", value: "1.5MiB", expected: 3 << 19},
+		{name: "lower case and spaces", value: " 2 mb ", expected: 2_000_000},
+		{name: "terabytes", value: "1TB", expected: 1_000_000_000_000},
+		{name: "zero", value: "0", expected: 0},
+		{name: "unknown unit", value: "10XB", wantErr: `invalid size "10XB"`},
+		{name: "unit only", value: "MB", wantErr: `invalid size "MB"`},
+		{name: "negative", value: "-1KB", wantErr: `invalid size "-1KB"`},
+		{name: "not a number", value: "NaN", wantErr: `invalid size "NaN"`},
+		{name: "empty", value: "", wantErr: `invalid size ""`},
+		{name: "overflow", value: "1e30GB", wantErr: `size "1e30GB" is too large`},
+		{name: "infinity", value: "Inf", wantErr: `size "Inf" is too large`},
+		{name: "original value quoted", value: " 5 parsecs", wantErr: `invalid size " 5 parsecs"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var size byteSize
+			err := size.Set(tc.value)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("expected error %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if size != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, size)
+			}
+		})
+	}
+}
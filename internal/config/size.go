@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"TB", 1000 * 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// byteSize is a flag value holding a size in bytes, such as "20GB" or
+// "512MiB". A plain number is taken as bytes.
+type byteSize int64
+
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Set(arg string) error {
+	value := strings.TrimSpace(arg)
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if len(value) > len(unit.suffix) && strings.EqualFold(value[len(value)-len(unit.suffix):], unit.suffix) {
+			value = strings.TrimSpace(value[:len(value)-len(unit.suffix)])
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || math.IsNaN(n) {
+		return fmt.Errorf("invalid size %q", arg)
+	}
+
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit either.
+	size := n * float64(multiplier)
+	if size >= math.MaxInt64 {
+		return fmt.Errorf("size %q is too large", arg)
+	}
+
+	*b = byteSize(size)
+	return nil
+}
+
+func (b *byteSize) Type() string {
+	return "size"
+}
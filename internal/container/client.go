@@ -5,17 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 )
 
 type ClientOptions struct {
 	LabelEnabled bool
+	// HealthcheckOnly restricts all monitoring, not only health alerts, to
+	// containers that define a healthcheck.
+	HealthcheckOnly bool
+	// Host names the Docker endpoint in alerts. When empty, the name the
+	// daemon reports for itself is used.
+	Host string
 }
 
 type Client struct {
 	SDK  DockerSDK
 	Opts *ClientOptions
+
+	hostMu       sync.Mutex
+	host         string
+	hostFailedAt time.Time
 }
 
 func NewClient(dockerSDK DockerSDK, opts *ClientOptions) (*Client, error) {
@@ -32,10 +45,38 @@ func (dc *Client) RunningContainers(ctx context.Context) ([]Container, error) {
 	}
 
 	containers := ConvertContainers(dockerContainers)
+
+	host := dc.hostName(ctx)
+	for i := range containers {
+		containers[i].Host = host
+	}
+
 	return containers, nil
 }
 
-func (dc *Client) ContainerLogs(ctx context.Context, containerID, since string, tail int) ([]byte, error) {
+func (dc *Client) ContainerInspect(ctx context.Context, containerID string) (*Container, error) {
+	if containerID == "" {
+		return nil, errors.New("container ID cannot be empty")
+	}
+
+	inspectCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	inspect, err := dc.SDK.ContainerInspect(inspectCtx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	container := ConvertInspect(inspect)
+	container.Host = dc.hostName(ctx)
+	return &container, nil
+}
+
+// ContainerLogs opens the logs of the container since the given time, or its
+// last tail lines. Opening the stream is bounded by PingTimeout, reading it
+// only by ctx, so that a large backlog is not cut short. The stream must be
+// closed.
+func (dc *Client) ContainerLogs(ctx context.Context, containerID, since string, tail int) (*docker.LogStream, error) {
 	if ctx == nil {
 		panic("context must not be nil")
 	}
@@ -44,22 +85,247 @@ func (dc *Client) ContainerLogs(ctx context.Context, containerID, since string,
 		return nil, errors.New("container ID cannot be empty")
 	}
 
-	logCtx, cancel := context.WithTimeout(ctx, PingTimeout)
-	defer cancel()
+	// The request is canceled if the response headers take longer than
+	// PingTimeout. Once they arrived, the body lives until it is closed.
+	logCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(PingTimeout, cancel)
 
 	dockerOpts := ContainerLogsOptions(since, tail)
 	logs, err := dc.SDK.ContainerLogs(logCtx, containerID, dockerOpts)
+	if !timer.Stop() && err == nil {
+		logs.Close()
+		err = context.DeadlineExceeded
+	}
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to get logs for container %s: %w", containerID, err)
 	}
-	defer logs.Close()
 
-	buf, err := io.ReadAll(logs)
+	logs.ReadCloser = &cancelReadCloser{ReadCloser: logs.ReadCloser, cancel: cancel}
+	return logs, nil
+}
+
+// cancelReadCloser cancels the context of a request once its body is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+func (dc *Client) ContainerState(ctx context.Context, containerID string) (*State, error) {
+	if containerID == "" {
+		return nil, errors.New("container ID cannot be empty")
+	}
+
+	inspectCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	inspect, err := dc.SDK.ContainerInspect(inspectCtx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	state := ConvertState(inspect)
+	return &state, nil
+}
+
+func (dc *Client) ContainerStats(ctx context.Context, containerID string) (*Stats, error) {
+	if containerID == "" {
+		return nil, errors.New("container ID cannot be empty")
+	}
+
+	// The daemon samples twice, a second apart, so allow for that on top of
+	// the usual timeout.
+	statsCtx, cancel := context.WithTimeout(ctx, PingTimeout+StatsSampleInterval)
+	defer cancel()
+
+	dockerStats, err := dc.SDK.ContainerStats(statsCtx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats for container %s: %w", containerID, err)
+	}
+
+	stats := ConvertStats(dockerStats)
+	return &stats, nil
+}
+
+func (dc *Client) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	dfCtx, cancel := context.WithTimeout(ctx, DiskUsageTimeout)
+	defer cancel()
+
+	dockerUsage, err := dc.SDK.DiskUsage(dfCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+
+	usage := ConvertDiskUsage(dockerUsage)
+	usage.Host = dc.hostName(ctx)
+	return &usage, nil
+}
+
+// FollowLogs opens a long-lived log stream for the container. The stream
+// stays open until the container stops or ctx is canceled.
+func (dc *Client) FollowLogs(ctx context.Context, containerID, since string) (*docker.LogStream, error) {
+	if containerID == "" {
+		return nil, errors.New("container ID cannot be empty")
+	}
+
+	logs, err := dc.SDK.ContainerLogs(ctx, containerID, FollowLogsOptions(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow logs for container %s: %w", containerID, err)
+	}
+
+	return logs, nil
+}
+
+// Services lists the Swarm services. It fails on daemons that are not Swarm
+// managers.
+func (dc *Client) Services(ctx context.Context) ([]Service, error) {
+	listCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	dockerServices, err := dc.SDK.ServiceList(listCtx, docker.ServiceListOptions{
+		Filters: ServiceFilters(dc.Opts),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	services := ConvertServices(dockerServices)
+
+	host := dc.hostName(ctx)
+	for i := range services {
+		services[i].Host = host
+	}
+
+	return services, nil
+}
+
+func (dc *Client) ServiceTasks(ctx context.Context, serviceID string) ([]Task, error) {
+	if serviceID == "" {
+		return nil, errors.New("service ID cannot be empty")
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	dockerTasks, err := dc.SDK.TaskList(listCtx, docker.TaskListOptions{
+		Filters: ServiceTaskFilters(serviceID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks of service %s: %w", serviceID, err)
+	}
+
+	return ConvertTasks(dockerTasks), nil
+}
+
+// FollowServiceLogs opens a long-lived log stream of every task of the
+// service. Lines are prefixed with log details, see docker.ParseLogDetails.
+func (dc *Client) FollowServiceLogs(ctx context.Context, serviceID, since string) (*docker.LogStream, error) {
+	if serviceID == "" {
+		return nil, errors.New("service ID cannot be empty")
+	}
+
+	logs, err := dc.SDK.ServiceLogs(ctx, serviceID, FollowServiceLogsOptions(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow logs for service %s: %w", serviceID, err)
+	}
+
+	return logs, nil
+}
+
+func (dc *Client) ContainerEvents(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	messages, sdkErrs := dc.SDK.Events(ctx, docker.EventsOptions{
+		Filters: ContainerEventFilters(dc.Opts),
+	})
+
+	go func() {
+		defer close(events)
+
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					errs <- <-sdkErrs
+					return
+				}
+
+				event := ConvertEvent(message)
+				event.Container.Host = dc.hostName(ctx)
+
+				if event.Action == EventStart && !dc.selected(ctx, event.Container.ID) {
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// selected reports whether a started container passes the filters that the
+// events endpoint cannot apply.
+func (dc *Client) selected(ctx context.Context, containerID string) bool {
+	if !dc.Opts.HealthcheckOnly {
+		return true
+	}
+
+	state, err := dc.ContainerState(ctx, containerID)
+	if err != nil {
+		slog.Debug("Failed to inspect started container", "containerID", containerID, "err", err)
+		return false
+	}
+
+	return state.Health != ""
+}
+
+// hostName returns the name of the Docker host. Unless set in the options it
+// is looked up once and cached; on failure an empty name is returned and the
+// lookup is only retried after HostInfoRetryInterval, so that an unreachable
+// daemon does not stall every caller for PingTimeout.
+func (dc *Client) hostName(ctx context.Context) string {
+	if dc.Opts.Host != "" {
+		return dc.Opts.Host
+	}
+
+	dc.hostMu.Lock()
+	defer dc.hostMu.Unlock()
+
+	if dc.host != "" {
+		return dc.host
+	}
+	if !dc.hostFailedAt.IsZero() && time.Since(dc.hostFailedAt) < HostInfoRetryInterval {
+		return ""
+	}
+
+	infoCtx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	info, err := dc.SDK.Info(infoCtx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read logs for container %s: %w", containerID, err)
+		slog.Debug("Failed to get Docker host info", "err", err)
+		dc.hostFailedAt = time.Now()
+		return ""
 	}
 
-	return buf, nil
+	dc.host = info.Name
+	return dc.host
 }
 
 func (dc *Client) Close() error {
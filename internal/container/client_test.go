@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -13,7 +14,15 @@ import (
 
 type mockDockerSDK struct {
 	containerListFunc  func(ctx context.Context, options docker.ContainerListOptions) ([]docker.Container, error)
-	containerLogsFunc  func(ctx context.Context, container string, options docker.ContainerLogsOptions) (io.ReadCloser, error)
+	containerLogsFunc  func(ctx context.Context, container string, options docker.ContainerLogsOptions) (*docker.LogStream, error)
+	inspectFunc        func(ctx context.Context, container string) (docker.ContainerJSON, error)
+	statsFunc          func(ctx context.Context, container string) (docker.StatsJSON, error)
+	diskUsageFunc      func(ctx context.Context) (docker.DiskUsage, error)
+	serviceListFunc    func(ctx context.Context, options docker.ServiceListOptions) ([]docker.Service, error)
+	taskListFunc       func(ctx context.Context, options docker.TaskListOptions) ([]docker.Task, error)
+	serviceLogsFunc    func(ctx context.Context, service string, options docker.ContainerLogsOptions) (*docker.LogStream, error)
+	infoFunc           func(ctx context.Context) (docker.Info, error)
+	eventsFunc         func(ctx context.Context, options docker.EventsOptions) (<-chan docker.Event, <-chan error)
 	pingFunc           func(ctx context.Context) (string, error)
 	closeFunc          func()
 	containerListCalls int
@@ -27,11 +36,46 @@ func (m *mockDockerSDK) ContainerList(ctx context.Context, options docker.Contai
 	return m.containerListFunc(ctx, options)
 }
 
-func (m *mockDockerSDK) ContainerLogs(ctx context.Context, container string, options docker.ContainerLogsOptions) (io.ReadCloser, error) {
+func (m *mockDockerSDK) ContainerLogs(ctx context.Context, container string, options docker.ContainerLogsOptions) (*docker.LogStream, error) {
 	m.containerLogsCalls++
 	return m.containerLogsFunc(ctx, container, options)
 }
 
+func (m *mockDockerSDK) ContainerInspect(ctx context.Context, container string) (docker.ContainerJSON, error) {
+	return m.inspectFunc(ctx, container)
+}
+
+func (m *mockDockerSDK) ContainerStats(ctx context.Context, container string) (docker.StatsJSON, error) {
+	return m.statsFunc(ctx, container)
+}
+
+func (m *mockDockerSDK) Events(ctx context.Context, options docker.EventsOptions) (<-chan docker.Event, <-chan error) {
+	return m.eventsFunc(ctx, options)
+}
+
+func (m *mockDockerSDK) DiskUsage(ctx context.Context) (docker.DiskUsage, error) {
+	return m.diskUsageFunc(ctx)
+}
+
+func (m *mockDockerSDK) ServiceList(ctx context.Context, options docker.ServiceListOptions) ([]docker.Service, error) {
+	return m.serviceListFunc(ctx, options)
+}
+
+func (m *mockDockerSDK) TaskList(ctx context.Context, options docker.TaskListOptions) ([]docker.Task, error) {
+	return m.taskListFunc(ctx, options)
+}
+
+func (m *mockDockerSDK) ServiceLogs(ctx context.Context, service string, options docker.ContainerLogsOptions) (*docker.LogStream, error) {
+	return m.serviceLogsFunc(ctx, service, options)
+}
+
+func (m *mockDockerSDK) Info(ctx context.Context) (docker.Info, error) {
+	if m.infoFunc == nil {
+		return docker.Info{}, nil
+	}
+	return m.infoFunc(ctx)
+}
+
 func (m *mockDockerSDK) Ping(ctx context.Context) (string, error) {
 	m.pingCalls++
 	return m.pingFunc(ctx)
@@ -202,29 +246,29 @@ func TestContainerLogs(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockSDK := &mockDockerSDK{
-				containerLogsFunc: func(ctx context.Context, container string, options docker.ContainerLogsOptions) (io.ReadCloser, error) {
+				containerLogsFunc: func(ctx context.Context, container string, options docker.ContainerLogsOptions) (*docker.LogStream, error) {
 					if tc.mockError != nil {
 						return nil, tc.mockError
 					}
-					return &mockReadCloser{
+					return &docker.LogStream{ReadCloser: &mockReadCloser{
 						Reader: strings.NewReader(tc.mockLogs),
 						closeFunc: func() error {
 							return nil
 						},
-					}, nil
+					}}, nil
 				},
 				closeFunc: func() {
 				},
 			}
 
 			if tc.readError != nil {
-				mockSDK.containerLogsFunc = func(ctx context.Context, container string, options docker.ContainerLogsOptions) (io.ReadCloser, error) {
-					return &mockReadCloser{
+				mockSDK.containerLogsFunc = func(ctx context.Context, container string, options docker.ContainerLogsOptions) (*docker.LogStream, error) {
+					return &docker.LogStream{ReadCloser: &mockReadCloser{
 						Reader: &errorReader{err: tc.readError},
 						closeFunc: func() error {
 							return nil
 						},
-					}, nil
+					}}, nil
 				}
 			}
 
@@ -247,6 +291,12 @@ func TestContainerLogs(t *testing.T) {
 				return
 			}
 
+			var output []byte
+			if err == nil {
+				output, err = io.ReadAll(logs)
+				logs.Close()
+			}
+
 			if tc.expectedError && err == nil {
 				t.Error("expected error, but got none")
 			}
@@ -261,14 +311,43 @@ func TestContainerLogs(t *testing.T) {
 			}
 
 			if !tc.expectedError {
-				if string(logs) != tc.expectedOutput {
-					t.Errorf("expected logs '%s', got '%s'", tc.expectedOutput, string(logs))
+				if string(output) != tc.expectedOutput {
+					t.Errorf("expected logs '%s', got '%s'", tc.expectedOutput, string(output))
 				}
 			}
 		})
 	}
 }
 
+func TestContainerLogs_readNotBounded(t *testing.T) {
+	var logCtx context.Context
+	mockSDK := &mockDockerSDK{
+		containerLogsFunc: func(ctx context.Context, container string, options docker.ContainerLogsOptions) (*docker.LogStream, error) {
+			logCtx = ctx
+			return &docker.LogStream{ReadCloser: io.NopCloser(strings.NewReader("log data"))}, nil
+		},
+	}
+	client := &container.Client{SDK: mockSDK, Opts: &container.ClientOptions{}}
+
+	logs, err := client.ContainerLogs(context.Background(), "container1", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only opening the stream is bounded by PingTimeout.
+	if _, ok := logCtx.Deadline(); ok {
+		t.Error("expected no deadline on reading the logs")
+	}
+	if logCtx.Err() != nil {
+		t.Errorf("expected the request to live until the logs are closed, got %v", logCtx.Err())
+	}
+
+	logs.Close()
+	if !errors.Is(logCtx.Err(), context.Canceled) {
+		t.Errorf("expected the request to be canceled once the logs are closed, got %v", logCtx.Err())
+	}
+}
+
 type errorReader struct {
 	err error
 }
@@ -334,3 +413,544 @@ func TestClose(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerEvents(t *testing.T) {
+	streamErr := errors.New("stream closed")
+
+	mockSDK := &mockDockerSDK{
+		eventsFunc: func(ctx context.Context, options docker.EventsOptions) (<-chan docker.Event, <-chan error) {
+			messages := make(chan docker.Event, 2)
+			errs := make(chan error, 1)
+
+			messages <- docker.Event{
+				Type:     "container",
+				Action:   "start",
+				Actor:    docker.EventActor{ID: "container1", Attributes: map[string]string{"name": "web"}},
+				TimeNano: 1678881720000000000,
+			}
+			messages <- docker.Event{
+				Type:   "container",
+				Action: "health_status: unhealthy",
+				Actor:  docker.EventActor{ID: "container2"},
+				Time:   1678881721,
+			}
+			errs <- streamErr
+			close(messages)
+
+			return messages, errs
+		},
+		closeFunc: func() {
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{},
+	}
+
+	events, errs := client.ContainerEvents(context.Background())
+
+	var got []container.Event
+	for event := range events {
+		got = append(got, event)
+	}
+
+	if err := <-errs; !errors.Is(err, streamErr) {
+		t.Errorf("expected error %v, got %v", streamErr, err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+
+	if got[0].Action != container.EventStart || got[0].Container.ID != "container1" || got[0].Container.Name != "web" {
+		t.Errorf("unexpected first event: %+v", got[0])
+	}
+	if got[0].Time.UnixNano() != 1678881720000000000 {
+		t.Errorf("expected nanosecond timestamp, got %v", got[0].Time)
+	}
+
+	if got[1].Action != container.EventHealthStatus || got[1].Status != "unhealthy" {
+		t.Errorf("expected health_status/unhealthy, got %s/%s", got[1].Action, got[1].Status)
+	}
+	if got[1].Container.Name != "container2" {
+		t.Errorf("expected name to fall back to ID, got %s", got[1].Container.Name)
+	}
+}
+
+func TestContainerState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		inspect       docker.ContainerJSON
+		inspectErr    error
+		expected      container.State
+		expectedError bool
+	}{
+		{
+			name: "oom killed container",
+			inspect: docker.ContainerJSON{
+				ID:           "container1",
+				RestartCount: 3,
+				State: &docker.ContainerState{
+					Status:    "exited",
+					ExitCode:  137,
+					OOMKilled: true,
+				},
+			},
+			expected: container.State{
+				Status:       "exited",
+				ExitCode:     137,
+				OOMKilled:    true,
+				RestartCount: 3,
+			},
+		},
+		{
+			name: "unhealthy container",
+			inspect: docker.ContainerJSON{
+				ID: "container1",
+				State: &docker.ContainerState{
+					Status:  "running",
+					Running: true,
+					Health: &docker.Health{
+						Status:        "unhealthy",
+						FailingStreak: 3,
+						Log: []docker.HealthcheckResult{
+							{ExitCode: 1, Output: "connection refused"},
+							{ExitCode: 1, Output: "timeout"},
+							{ExitCode: 0, Output: "ok"},
+						},
+					},
+				},
+			},
+			expected: container.State{
+				Status:          "running",
+				Running:         true,
+				Health:          "unhealthy",
+				FailingStreak:   3,
+				LastFailedProbe: &container.HealthProbe{ExitCode: 1, Output: "timeout"},
+			},
+		},
+		{
+			name:     "missing state",
+			inspect:  docker.ContainerJSON{ID: "container1", RestartCount: 1},
+			expected: container.State{RestartCount: 1},
+		},
+		{
+			name:          "inspect error",
+			inspectErr:    errors.New("docker error"),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSDK := &mockDockerSDK{
+				inspectFunc: func(ctx context.Context, id string) (docker.ContainerJSON, error) {
+					return tc.inspect, tc.inspectErr
+				},
+				closeFunc: func() {
+				},
+			}
+
+			client := &container.Client{
+				SDK:  mockSDK,
+				Opts: &container.ClientOptions{},
+			}
+
+			state, err := client.ContainerState(context.Background(), "container1")
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("expected error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(*state, tc.expected) {
+				t.Errorf("expected state %+v, got %+v", tc.expected, *state)
+			}
+		})
+	}
+}
+
+func TestContainerInspect(t *testing.T) {
+	mockSDK := &mockDockerSDK{
+		inspectFunc: func(ctx context.Context, id string) (docker.ContainerJSON, error) {
+			return docker.ContainerJSON{
+				ID:      id,
+				Name:    "/shop-web-1",
+				Created: "2023-03-15T12:00:00.123456789Z",
+				Image:   "sha256:0123456789",
+				State:   &docker.ContainerState{Status: "running"},
+				Config: &docker.ContainerConfig{
+					Image: "shop/web:1.2",
+					Labels: map[string]string{
+						container.LabelComposeProject: "shop",
+						container.LabelComposeService: "web",
+					},
+				},
+			}, nil
+		},
+		infoFunc: func(ctx context.Context) (docker.Info, error) {
+			return docker.Info{Name: "docker-host-1"}, nil
+		},
+		closeFunc: func() {
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{},
+	}
+
+	c, err := client.ContainerInspect(context.Background(), "container1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.ID != "container1" || c.Name != "shop-web-1" {
+		t.Errorf("unexpected container identity: %+v", c)
+	}
+	if c.Image != "shop/web:1.2" {
+		t.Errorf("expected image from config, got %s", c.Image)
+	}
+	if c.State != "running" {
+		t.Errorf("expected state running, got %s", c.State)
+	}
+	if c.ComposeProject() != "shop" || c.ComposeService() != "web" {
+		t.Errorf("expected compose shop/web, got %s/%s", c.ComposeProject(), c.ComposeService())
+	}
+	if c.Host != "docker-host-1" {
+		t.Errorf("expected host docker-host-1, got %s", c.Host)
+	}
+	if c.Created.Nanosecond() != 123456789 {
+		t.Errorf("expected created time to be parsed, got %v", c.Created)
+	}
+
+	if _, err := client.ContainerInspect(context.Background(), ""); err == nil {
+		t.Error("expected error for empty container ID, but got none")
+	}
+
+	client.Opts.Host = "edge-1"
+	c, err = client.ContainerInspect(context.Background(), "container1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Host != "edge-1" {
+		t.Errorf("expected endpoint name edge-1 as host, got %s", c.Host)
+	}
+}
+
+func TestContainerInspect_hostInfoFailure(t *testing.T) {
+	infoCalls := 0
+	mockSDK := &mockDockerSDK{
+		inspectFunc: func(ctx context.Context, id string) (docker.ContainerJSON, error) {
+			return docker.ContainerJSON{ID: id, State: &docker.ContainerState{Status: "running"}}, nil
+		},
+		infoFunc: func(ctx context.Context) (docker.Info, error) {
+			infoCalls++
+			return docker.Info{}, docker.ErrDaemonUnavailable
+		},
+		closeFunc: func() {
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{},
+	}
+
+	for range 3 {
+		c, err := client.ContainerInspect(context.Background(), "container1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Host != "" {
+			t.Errorf("expected no host name, got %s", c.Host)
+		}
+	}
+
+	if infoCalls != 1 {
+		t.Errorf("expected the failed lookup not to be retried right away, got %d lookups", infoCalls)
+	}
+}
+
+func TestContainerEvents_healthcheckOnly(t *testing.T) {
+	mockSDK := &mockDockerSDK{
+		eventsFunc: func(ctx context.Context, options docker.EventsOptions) (<-chan docker.Event, <-chan error) {
+			messages := make(chan docker.Event, 2)
+			errs := make(chan error, 1)
+
+			messages <- docker.Event{Type: "container", Action: "start", Actor: docker.EventActor{ID: "plain"}}
+			messages <- docker.Event{Type: "container", Action: "start", Actor: docker.EventActor{ID: "checked"}}
+			errs <- io.EOF
+			close(messages)
+
+			return messages, errs
+		},
+		inspectFunc: func(ctx context.Context, id string) (docker.ContainerJSON, error) {
+			state := &docker.ContainerState{Status: "running"}
+			if id == "checked" {
+				state.Health = &docker.Health{Status: "starting"}
+			}
+			return docker.ContainerJSON{ID: id, State: state}, nil
+		},
+		closeFunc: func() {
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{HealthcheckOnly: true},
+	}
+
+	events, _ := client.ContainerEvents(context.Background())
+
+	var ids []string
+	for event := range events {
+		ids = append(ids, event.Container.ID)
+	}
+
+	if len(ids) != 1 || ids[0] != "checked" {
+		t.Errorf("expected only the container with a healthcheck, got %v", ids)
+	}
+}
+
+func TestContainerStats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stats    docker.StatsJSON
+		expected container.Stats
+	}{
+		{
+			name: "cgroup v2",
+			stats: docker.StatsJSON{
+				CPUStats: docker.CPUStats{
+					CPUUsage:    docker.CPUUsage{TotalUsage: 300_000_000},
+					SystemUsage: 2_000_000_000,
+					OnlineCPUs:  4,
+				},
+				PreCPUStats: docker.CPUStats{
+					CPUUsage:    docker.CPUUsage{TotalUsage: 100_000_000},
+					SystemUsage: 1_000_000_000,
+				},
+				MemoryStats: docker.MemoryStats{
+					Usage: 600,
+					Limit: 1000,
+					Stats: map[string]uint64{"inactive_file": 100},
+				},
+				PidsStats: docker.PidsStats{Current: 12, Limit: 100},
+			},
+			expected: container.Stats{
+				CPUPercent:    80,
+				MemoryUsage:   500,
+				MemoryLimit:   1000,
+				MemoryPercent: 50,
+				Pids:          12,
+				PidsLimit:     100,
+			},
+		},
+		{
+			name: "cgroup v1 without online cpus",
+			stats: docker.StatsJSON{
+				CPUStats: docker.CPUStats{
+					CPUUsage:    docker.CPUUsage{TotalUsage: 200, PercpuUsage: []uint64{100, 100}},
+					SystemUsage: 1000,
+				},
+				MemoryStats: docker.MemoryStats{
+					Usage: 400,
+					Limit: 800,
+					Stats: map[string]uint64{"total_inactive_file": 200},
+				},
+			},
+			expected: container.Stats{
+				CPUPercent:    40,
+				MemoryUsage:   200,
+				MemoryLimit:   800,
+				MemoryPercent: 25,
+			},
+		},
+		{
+			name:     "first sample",
+			stats:    docker.StatsJSON{},
+			expected: container.Stats{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSDK := &mockDockerSDK{
+				statsFunc: func(ctx context.Context, id string) (docker.StatsJSON, error) {
+					return tc.stats, nil
+				},
+				closeFunc: func() {
+				},
+			}
+
+			client := &container.Client{
+				SDK:  mockSDK,
+				Opts: &container.ClientOptions{},
+			}
+
+			stats, err := client.ContainerStats(context.Background(), "container1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if *stats != tc.expected {
+				t.Errorf("expected stats %+v, got %+v", tc.expected, *stats)
+			}
+		})
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	mockSDK := &mockDockerSDK{
+		diskUsageFunc: func(ctx context.Context) (docker.DiskUsage, error) {
+			return docker.DiskUsage{
+				LayersSize: 900,
+				Images: []docker.ImageSummary{
+					{ID: "sha256:0123456789abcdef0123", RepoTags: []string{"<none>:<none>"}, Size: 300},
+					{ID: "sha256:fedcba9876543210fedc", RepoTags: []string{"nginx:latest"}, Size: 700, Containers: 2},
+				},
+				Volumes: []docker.Volume{
+					{Name: "data", UsageData: &docker.VolumeUsageData{Size: 50, RefCount: 1}},
+					{Name: "unknown", UsageData: &docker.VolumeUsageData{Size: -1}},
+					{Name: "cache", UsageData: &docker.VolumeUsageData{Size: 200}},
+				},
+				BuildCache: []docker.BuildCache{
+					{ID: "abc", Description: "mount / from exec /bin/sh -c make", Size: 40},
+					{ID: "def", Size: 60, Shared: true},
+				},
+			}, nil
+		},
+		infoFunc: func(ctx context.Context) (docker.Info, error) {
+			return docker.Info{Name: "docker-host-1"}, nil
+		},
+	}
+
+	client, _ := container.NewClient(mockSDK, &container.ClientOptions{})
+
+	usage, err := client.DiskUsage(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &container.DiskUsage{
+		Host:           "docker-host-1",
+		ImagesSize:     900,
+		VolumesSize:    250,
+		BuildCacheSize: 40,
+		Images: []container.DiskUsageItem{
+			{Name: "nginx:latest", Size: 700, InUse: true},
+			{Name: "0123456789ab", Size: 300},
+		},
+		Volumes: []container.DiskUsageItem{
+			{Name: "cache", Size: 200},
+			{Name: "data", Size: 50, InUse: true},
+		},
+		BuildCache: []container.DiskUsageItem{
+			{Name: "def", Size: 60},
+			{Name: "mount / from exec /bin/sh -c make", Size: 40},
+		},
+	}
+
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %+v, got %+v", expected, usage)
+	}
+}
+
+func TestContainerState_typedErrors(t *testing.T) {
+	mockSDK := &mockDockerSDK{
+		inspectFunc: func(ctx context.Context, id string) (docker.ContainerJSON, error) {
+			return docker.ContainerJSON{}, &docker.APIError{
+				StatusCode: 404,
+				Method:     "GET",
+				Path:       "/v1.45/containers/" + id + "/json",
+				Message:    "No such container: " + id,
+			}
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{},
+	}
+
+	_, err := client.ContainerState(context.Background(), "container1")
+	if !errors.Is(err, docker.ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if errors.Is(err, docker.ErrPermission) {
+		t.Errorf("expected no permission error, got %v", err)
+	}
+
+	var apiErr *docker.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "No such container: container1" {
+		t.Errorf("expected the API error to be unwrapped, got %v", err)
+	}
+}
+
+func TestServices(t *testing.T) {
+	mockSDK := &mockDockerSDK{
+		serviceListFunc: func(ctx context.Context, options docker.ServiceListOptions) ([]docker.Service, error) {
+			if options.Filters.Len() != 1 {
+				t.Errorf("expected the label filter, got %d filters", options.Filters.Len())
+			}
+			return []docker.Service{
+				{
+					ID: "service1",
+					Spec: docker.ServiceSpec{
+						Name:   "shop_web",
+						Labels: map[string]string{container.LabelEnableKey: container.LabelEnableValue},
+						TaskTemplate: docker.TaskSpec{
+							ContainerSpec: &docker.ContainerSpec{Image: "shop/web:1.2@sha256:0123"},
+						},
+					},
+				},
+			}, nil
+		},
+		taskListFunc: func(ctx context.Context, options docker.TaskListOptions) ([]docker.Task, error) {
+			return []docker.Task{
+				{ID: "task1", ServiceID: "service1", NodeID: "node1", Slot: 2},
+			}, nil
+		},
+		infoFunc: func(ctx context.Context) (docker.Info, error) {
+			return docker.Info{Name: "manager-1"}, nil
+		},
+	}
+
+	client := &container.Client{
+		SDK:  mockSDK,
+		Opts: &container.ClientOptions{LabelEnabled: true},
+	}
+
+	services, err := client.Services(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []container.Service{
+		{
+			ID:     "service1",
+			Name:   "shop_web",
+			Image:  "shop/web:1.2@sha256:0123",
+			Labels: map[string]string{container.LabelEnableKey: container.LabelEnableValue},
+			Host:   "manager-1",
+		},
+	}
+	if !reflect.DeepEqual(services, expected) {
+		t.Errorf("expected %+v, got %+v", expected, services)
+	}
+
+	tasks, err := client.ServiceTasks(context.Background(), "service1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Slot != 2 || tasks[0].NodeID != "node1" {
+		t.Errorf("unexpected tasks: %+v", tasks)
+	}
+}
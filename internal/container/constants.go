@@ -5,6 +5,60 @@ import "time"
 const (
 	LabelEnableKey   = "com.andvarfolomeev.dockernotifier.enable"
 	LabelEnableValue = "true"
-	PingTimeout      = 5 * time.Second
-	ShortIDLen       = 12
+
+	// LabelLogFormat selects the format of the container log lines, see
+	// logfilter.Format.
+	LabelLogFormat = "com.andvarfolomeev.dockernotifier.log-format"
+
+	LabelComposeProject = "com.docker.compose.project"
+	LabelComposeService = "com.docker.compose.service"
+
+	// LabelSwarmServiceID is set on task containers of Swarm services. Log
+	// details of service logs use the same keys.
+	LabelSwarmServiceID = "com.docker.swarm.service.id"
+	LabelSwarmTaskID    = "com.docker.swarm.task.id"
+
+	PingTimeout = 5 * time.Second
+	ShortIDLen  = 12
+
+	// HostInfoRetryInterval is how long a failed lookup of the host name is
+	// remembered before the daemon is asked again.
+	HostInfoRetryInterval = time.Minute
+
+	StatsSampleInterval = time.Second
+	DiskUsageTimeout    = time.Minute
+)
+
+const (
+	EventStart        = "start"
+	EventDie          = "die"
+	EventDestroy      = "destroy"
+	EventRename       = "rename"
+	EventHealthStatus = "health_status"
+	EventOOM          = "oom"
+	EventKill         = "kill"
 )
+
+var WatchedEvents = []string{
+	EventStart,
+	EventDie,
+	EventDestroy,
+	EventRename,
+	EventHealthStatus,
+	EventOOM,
+	EventKill,
+}
+
+const (
+	HealthStarting  = "starting"
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+)
+
+// HealthStatuses are the health filter values matching every container with
+// a healthcheck.
+var HealthStatuses = []string{
+	HealthStarting,
+	HealthHealthy,
+	HealthUnhealthy,
+}
@@ -2,13 +2,56 @@ package container
 
 import (
 	"strings"
+	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 )
 
 type Container struct {
-	ID   string
-	Name string
+	ID      string
+	Name    string
+	Image   string
+	Labels  map[string]string
+	State   string
+	Created time.Time
+	// Host is the name of the Docker host the container runs on.
+	Host string
+	// Service and TaskSlot identify the Swarm task the container runs, when
+	// its logs are watched through its service.
+	Service  string
+	TaskSlot int
+}
+
+type State struct {
+	Status       string
+	Running      bool
+	ExitCode     int
+	OOMKilled    bool
+	RestartCount int
+	Error        string
+	// Health is the healthcheck status, empty for containers without a
+	// healthcheck.
+	Health        string
+	FailingStreak int
+	// LastFailedProbe is the most recent failing healthcheck probe, if any.
+	LastFailedProbe *HealthProbe
+}
+
+type HealthProbe struct {
+	ExitCode int
+	Output   string
+}
+
+func (c Container) ComposeProject() string {
+	return c.Labels[LabelComposeProject]
+}
+
+func (c Container) ComposeService() string {
+	return c.Labels[LabelComposeService]
+}
+
+func (c Container) LogFormat() string {
+	return c.Labels[LabelLogFormat]
 }
 
 func ContainerName(container docker.Container) string {
@@ -26,9 +69,72 @@ func ConvertContainers(dockerContainers []docker.Container) []Container {
 	containers := make([]Container, 0, len(dockerContainers))
 	for _, dockerContainer := range dockerContainers {
 		containers = append(containers, Container{
-			ID:   dockerContainer.ID,
-			Name: ContainerName(dockerContainer),
+			ID:      dockerContainer.ID,
+			Name:    ContainerName(dockerContainer),
+			Image:   dockerContainer.Image,
+			Labels:  dockerContainer.Labels,
+			State:   dockerContainer.State,
+			Created: time.Unix(dockerContainer.Created, 0),
 		})
 	}
 	return containers
 }
+
+func ConvertState(inspect docker.ContainerJSON) State {
+	state := State{
+		RestartCount: inspect.RestartCount,
+	}
+
+	if inspect.State != nil {
+		state.Status = inspect.State.Status
+		state.Running = inspect.State.Running
+		state.ExitCode = inspect.State.ExitCode
+		state.OOMKilled = inspect.State.OOMKilled
+		state.Error = inspect.State.Error
+
+		if health := inspect.State.Health; health != nil {
+			state.Health = health.Status
+			state.FailingStreak = health.FailingStreak
+
+			for i := len(health.Log) - 1; i >= 0; i-- {
+				if probe := health.Log[i]; probe.ExitCode != 0 {
+					state.LastFailedProbe = &HealthProbe{
+						ExitCode: probe.ExitCode,
+						Output:   probe.Output,
+					}
+					break
+				}
+			}
+		}
+	}
+
+	return state
+}
+
+func ConvertInspect(inspect docker.ContainerJSON) Container {
+	var names []string
+	if inspect.Name != "" {
+		names = []string{inspect.Name}
+	}
+
+	c := Container{
+		ID:    inspect.ID,
+		Name:  ContainerName(docker.Container{ID: inspect.ID, Names: names}),
+		Image: inspect.Image,
+	}
+
+	if inspect.Config != nil {
+		c.Image = inspect.Config.Image
+		c.Labels = inspect.Config.Labels
+	}
+
+	if inspect.State != nil {
+		c.State = inspect.State.Status
+	}
+
+	if created, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
+		c.Created = created
+	}
+
+	return c
+}
@@ -2,6 +2,7 @@ package container_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
 	"github.com/andvarfolomeev/docker-notifier/internal/docker"
@@ -90,6 +91,29 @@ func TestConvertContainers(t *testing.T) {
 				{ID: "container3", Name: "test-container-3"},
 			},
 		},
+		{
+			name: "container with metadata",
+			dockerContainers: []docker.Container{
+				{
+					ID:      "container1",
+					Names:   []string{"/shop-web-1"},
+					Image:   "shop/web:1.2",
+					Labels:  map[string]string{container.LabelComposeProject: "shop"},
+					State:   "running",
+					Created: 1678881600,
+				},
+			},
+			expectedContainers: []container.Container{
+				{
+					ID:      "container1",
+					Name:    "shop-web-1",
+					Image:   "shop/web:1.2",
+					Labels:  map[string]string{container.LabelComposeProject: "shop"},
+					State:   "running",
+					Created: time.Unix(1678881600, 0),
+				},
+			},
+		},
 		{
 			name: "containers with different name formats",
 			dockerContainers: []docker.Container{
@@ -122,6 +146,54 @@ func TestConvertContainers(t *testing.T) {
 				if actual.ID != expected.ID || actual.Name != expected.Name {
 					t.Errorf("container %d mismatch: expected %+v, got %+v", i, expected, actual)
 				}
+				if actual.Image != expected.Image || actual.State != expected.State || actual.ComposeProject() != expected.ComposeProject() {
+					t.Errorf("container %d metadata mismatch: expected %+v, got %+v", i, expected, actual)
+				}
+				if expected.Created.Unix() > 0 && !actual.Created.Equal(expected.Created) {
+					t.Errorf("container %d created mismatch: expected %v, got %v", i, expected.Created, actual.Created)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskContainer(t *testing.T) {
+	service := container.Service{
+		ID:     "service1",
+		Name:   "shop_web",
+		Image:  "shop/web:1.2",
+		Labels: map[string]string{"team": "shop"},
+		Host:   "manager-1",
+	}
+
+	testCases := []struct {
+		name     string
+		task     container.Task
+		expected string
+	}{
+		{
+			name:     "replicated",
+			task:     container.Task{ID: "task1", NodeID: "node1", Slot: 3},
+			expected: "shop_web.3",
+		},
+		{
+			name:     "global",
+			task:     container.Task{ID: "task2", NodeID: "abcdefghijklmnopqrstuvwxy"},
+			expected: "shop_web.abcdefghijkl",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := container.TaskContainer(service, tc.task)
+			if c.Name != tc.expected {
+				t.Errorf("expected name %s, got %s", tc.expected, c.Name)
+			}
+			if c.ID != tc.task.ID || c.Service != "shop_web" || c.TaskSlot != tc.task.Slot {
+				t.Errorf("unexpected task container: %+v", c)
+			}
+			if c.Image != "shop/web:1.2" || c.Host != "manager-1" || c.Labels["team"] != "shop" {
+				t.Errorf("expected service details, got %+v", c)
 			}
 		})
 	}
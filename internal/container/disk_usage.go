@@ -0,0 +1,99 @@
+package container
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+)
+
+const noneTag = "<none>:<none>"
+
+type DiskUsage struct {
+	// Host is the name of the Docker host the usage was reported by.
+	Host string
+
+	ImagesSize     int64
+	VolumesSize    int64
+	BuildCacheSize int64
+
+	// Images, Volumes and BuildCache are sorted by size, largest first.
+	Images     []DiskUsageItem
+	Volumes    []DiskUsageItem
+	BuildCache []DiskUsageItem
+}
+
+type DiskUsageItem struct {
+	Name  string
+	Size  int64
+	InUse bool
+}
+
+// ConvertDiskUsage totals the sizes the same way `docker system df` does:
+// image layers shared between images are counted once and volumes whose size
+// the daemon did not compute are skipped.
+func ConvertDiskUsage(usage docker.DiskUsage) DiskUsage {
+	var du DiskUsage
+
+	for _, image := range usage.Images {
+		du.Images = append(du.Images, DiskUsageItem{
+			Name:  imageName(image),
+			Size:  image.Size,
+			InUse: image.Containers > 0,
+		})
+		du.ImagesSize += image.Size
+	}
+	if usage.LayersSize > 0 {
+		du.ImagesSize = usage.LayersSize
+	}
+
+	for _, volume := range usage.Volumes {
+		if volume.UsageData == nil || volume.UsageData.Size < 0 {
+			continue
+		}
+		du.Volumes = append(du.Volumes, DiskUsageItem{
+			Name:  volume.Name,
+			Size:  volume.UsageData.Size,
+			InUse: volume.UsageData.RefCount > 0,
+		})
+		du.VolumesSize += volume.UsageData.Size
+	}
+
+	for _, cache := range usage.BuildCache {
+		name := cache.Description
+		if name == "" {
+			name = cache.ID
+		}
+		du.BuildCache = append(du.BuildCache, DiskUsageItem{
+			Name:  name,
+			Size:  cache.Size,
+			InUse: cache.InUse,
+		})
+		if !cache.Shared {
+			du.BuildCacheSize += cache.Size
+		}
+	}
+
+	for _, items := range [][]DiskUsageItem{du.Images, du.Volumes, du.BuildCache} {
+		slices.SortStableFunc(items, func(a, b DiskUsageItem) int {
+			return cmp.Compare(b.Size, a.Size)
+		})
+	}
+
+	return du
+}
+
+func imageName(image docker.ImageSummary) string {
+	for _, tag := range image.RepoTags {
+		if tag != noneTag {
+			return tag
+		}
+	}
+
+	id := strings.TrimPrefix(image.ID, "sha256:")
+	if len(id) >= ShortIDLen {
+		return id[:ShortIDLen]
+	}
+	return id
+}
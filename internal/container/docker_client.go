@@ -2,19 +2,34 @@ package container
 
 import (
 	"context"
-	"io"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 )
 
 type DockerSDK interface {
 	ContainerList(context.Context, docker.ContainerListOptions) ([]docker.Container, error)
-	ContainerLogs(context.Context, string, docker.ContainerLogsOptions) (io.ReadCloser, error)
+	ContainerLogs(context.Context, string, docker.ContainerLogsOptions) (*docker.LogStream, error)
+	ContainerInspect(context.Context, string) (docker.ContainerJSON, error)
+	ContainerStats(context.Context, string) (docker.StatsJSON, error)
+	Events(context.Context, docker.EventsOptions) (<-chan docker.Event, <-chan error)
+	DiskUsage(context.Context) (docker.DiskUsage, error)
+	ServiceList(context.Context, docker.ServiceListOptions) ([]docker.Service, error)
+	TaskList(context.Context, docker.TaskListOptions) ([]docker.Task, error)
+	ServiceLogs(context.Context, string, docker.ContainerLogsOptions) (*docker.LogStream, error)
+	Info(context.Context) (docker.Info, error)
 	Ping(context.Context) (string, error)
 	Close()
 
 	// ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
 	// ContainerLogs(ctx context.Context, container string, options types.ContainerLogsOptions) (io.ReadCloser, error)
+	// ContainerInspect(ctx context.Context, container string) (types.ContainerJSON, error)
+	// ContainerStatsOneShot(ctx context.Context, container string) (types.ContainerStats, error)
+	// Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+	// DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	// ServiceList(ctx context.Context, options types.ServiceListOptions) ([]swarm.Service, error)
+	// TaskList(ctx context.Context, options types.TaskListOptions) ([]swarm.Task, error)
+	// ServiceLogs(ctx context.Context, serviceID string, options container.LogsOptions) (io.ReadCloser, error)
+	// Info(ctx context.Context) (system.Info, error)
 	// Ping(ctx context.Context) (types.Ping, error)
 	// Close() error
 }
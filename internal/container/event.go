@@ -0,0 +1,75 @@
+package container
+
+import (
+	"strings"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+)
+
+type Event struct {
+	// Action is the event name without its detail suffix, e.g. "health_status".
+	Action string
+	// Status is the detail suffix of the action, e.g. "unhealthy" for
+	// "health_status: unhealthy". It is empty for most events.
+	Status     string
+	Container  Container
+	Attributes map[string]string
+	Time       time.Time
+}
+
+func ConvertEvent(event docker.Event) Event {
+	action, status, _ := strings.Cut(event.Action, ":")
+
+	var ts time.Time
+	if event.TimeNano > 0 {
+		ts = time.Unix(0, event.TimeNano)
+	} else {
+		ts = time.Unix(event.Time, 0)
+	}
+
+	return Event{
+		Action: action,
+		Status: strings.TrimSpace(status),
+		Container: Container{
+			ID: event.Actor.ID,
+			Name: ContainerName(docker.Container{
+				ID:    event.Actor.ID,
+				Names: eventNames(event),
+			}),
+			Image:  event.Actor.Attributes["image"],
+			Labels: eventLabels(event),
+		},
+		Attributes: event.Actor.Attributes,
+		Time:       ts,
+	}
+}
+
+func eventNames(event docker.Event) []string {
+	if name := event.Actor.Attributes["name"]; name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// eventAttributes are the attributes the daemon adds to container events next
+// to the container labels. podId is added by Podman.
+var eventAttributes = map[string]bool{
+	"name":         true,
+	"image":        true,
+	"exitCode":     true,
+	"signal":       true,
+	"execDuration": true,
+	"oldName":      true,
+	"podId":        true,
+}
+
+func eventLabels(event docker.Event) map[string]string {
+	labels := make(map[string]string, len(event.Actor.Attributes))
+	for key, value := range event.Actor.Attributes {
+		if !eventAttributes[key] {
+			labels[key] = value
+		}
+	}
+	return labels
+}
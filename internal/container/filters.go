@@ -14,6 +14,12 @@ func RunningContainerFilters(opts *ClientOptions) docker.Filters {
 		filterArgs.Add("label", fmt.Sprintf("%s=%s", LabelEnableKey, LabelEnableValue))
 	}
 
+	if opts.HealthcheckOnly {
+		for _, status := range HealthStatuses {
+			filterArgs.Add("health", status)
+		}
+	}
+
 	return *filterArgs
 }
 
@@ -34,3 +40,48 @@ func ContainerLogsOptions(since string, tail int) docker.ContainerLogsOptions {
 
 	return opts
 }
+
+func ContainerEventFilters(opts *ClientOptions) docker.Filters {
+	filterArgs := docker.NewFilter()
+	filterArgs.Add("type", "container")
+
+	for _, event := range WatchedEvents {
+		filterArgs.Add("event", event)
+	}
+
+	if opts.LabelEnabled {
+		filterArgs.Add("label", fmt.Sprintf("%s=%s", LabelEnableKey, LabelEnableValue))
+	}
+
+	return *filterArgs
+}
+
+func FollowLogsOptions(since string) docker.ContainerLogsOptions {
+	opts := ContainerLogsOptions(since, 0)
+	opts.Follow = true
+	return opts
+}
+
+func ServiceFilters(opts *ClientOptions) docker.Filters {
+	filterArgs := docker.NewFilter()
+
+	if opts.LabelEnabled {
+		filterArgs.Add("label", fmt.Sprintf("%s=%s", LabelEnableKey, LabelEnableValue))
+	}
+
+	return *filterArgs
+}
+
+func ServiceTaskFilters(serviceID string) docker.Filters {
+	filterArgs := docker.NewFilter()
+	filterArgs.Add("service", serviceID)
+	return *filterArgs
+}
+
+// FollowServiceLogsOptions asks for log details, which carry the task ID of
+// every line.
+func FollowServiceLogsOptions(since string) docker.ContainerLogsOptions {
+	opts := FollowLogsOptions(since)
+	opts.Details = true
+	return opts
+}
@@ -10,9 +10,10 @@ import (
 
 func TestRunningContainerFilters(t *testing.T) {
 	testCases := []struct {
-		name          string
-		labelEnabled  bool
-		expectedCount int
+		name            string
+		labelEnabled    bool
+		healthcheckOnly bool
+		expectedCount   int
 	}{
 		{
 			name:          "status filter only",
@@ -24,12 +25,18 @@ func TestRunningContainerFilters(t *testing.T) {
 			labelEnabled:  true,
 			expectedCount: 2,
 		},
+		{
+			name:            "status and health filters",
+			healthcheckOnly: true,
+			expectedCount:   2,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			opts := &container.ClientOptions{
-				LabelEnabled: tc.labelEnabled,
+				LabelEnabled:    tc.labelEnabled,
+				HealthcheckOnly: tc.healthcheckOnly,
 			}
 
 			filterArgs := container.RunningContainerFilters(opts)
@@ -45,6 +52,13 @@ func TestRunningContainerFilters(t *testing.T) {
 				filterCount++
 			}
 
+			if tc.healthcheckOnly {
+				expectedFilter.Add("health", "starting")
+				expectedFilter.Add("health", "healthy")
+				expectedFilter.Add("health", "unhealthy")
+				filterCount++
+			}
+
 			expected, _ := expectedFilter.Encode()
 			actual, _ := filterArgs.Encode()
 
@@ -142,3 +156,96 @@ func TestContainerLogsOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerEventFilters(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labelEnabled  bool
+		expectedCount int
+	}{
+		{
+			name:          "type and event filters",
+			labelEnabled:  false,
+			expectedCount: 2,
+		},
+		{
+			name:          "type, event and label filters",
+			labelEnabled:  true,
+			expectedCount: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filterArgs := container.ContainerEventFilters(&container.ClientOptions{
+				LabelEnabled: tc.labelEnabled,
+			})
+
+			expectedFilter := docker.NewFilter()
+			expectedFilter.Add("type", "container")
+			for _, event := range container.WatchedEvents {
+				expectedFilter.Add("event", event)
+			}
+			if tc.labelEnabled {
+				expectedFilter.Add("label", fmt.Sprintf("%s=%s", container.LabelEnableKey, container.LabelEnableValue))
+			}
+
+			expected, _ := expectedFilter.Encode()
+			actual, _ := filterArgs.Encode()
+
+			if expected != actual {
+				t.Errorf("Filter mismatch: expected %s, got %s", expected, actual)
+			}
+
+			if filterArgs.Len() != tc.expectedCount {
+				t.Errorf("expected %d filters, got %d", tc.expectedCount, filterArgs.Len())
+			}
+		})
+	}
+}
+
+func TestFollowLogsOptions(t *testing.T) {
+	opts := container.FollowLogsOptions("2023-03-15T12:00:00Z")
+
+	if !opts.Follow {
+		t.Error("Follow field: expected true, got false")
+	}
+	if opts.Since != "2023-03-15T12:00:00Z" {
+		t.Errorf("Since field: expected '2023-03-15T12:00:00Z', got '%s'", opts.Since)
+	}
+	if !opts.Stdout || !opts.Stderr || !opts.Timestamp {
+		t.Errorf("expected stdout, stderr and timestamps to be enabled, got %+v", opts)
+	}
+	if opts.Tail != "" {
+		t.Errorf("Tail field: expected empty, got '%s'", opts.Tail)
+	}
+}
+
+func TestServiceFilters(t *testing.T) {
+	filterArgs := container.ServiceFilters(&container.ClientOptions{})
+	if filterArgs.Len() != 0 {
+		t.Errorf("expected no filters, got %d", filterArgs.Len())
+	}
+
+	filterArgs = container.ServiceFilters(&container.ClientOptions{LabelEnabled: true})
+
+	expectedFilter := docker.NewFilter()
+	expectedFilter.Add("label", fmt.Sprintf("%s=%s", container.LabelEnableKey, container.LabelEnableValue))
+
+	expected, _ := expectedFilter.Encode()
+	actual, _ := filterArgs.Encode()
+	if expected != actual {
+		t.Errorf("Filter mismatch: expected %s, got %s", expected, actual)
+	}
+}
+
+func TestFollowServiceLogsOptions(t *testing.T) {
+	opts := container.FollowServiceLogsOptions("2023-03-15T12:00:00Z")
+
+	if !opts.Follow || !opts.Details || !opts.Timestamp {
+		t.Errorf("expected follow, details and timestamps to be enabled, got %+v", opts)
+	}
+	if opts.Since != "2023-03-15T12:00:00Z" {
+		t.Errorf("Since field: expected '2023-03-15T12:00:00Z', got '%s'", opts.Since)
+	}
+}
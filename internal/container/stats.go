@@ -0,0 +1,59 @@
+package container
+
+import "github.com/andvarfolomeev/docker-notifier/internal/docker"
+
+type Stats struct {
+	CPUPercent    float64
+	MemoryUsage   uint64
+	MemoryLimit   uint64
+	MemoryPercent float64
+	Pids          uint64
+	PidsLimit     uint64
+}
+
+// ConvertStats computes usage the same way `docker stats` does: CPU usage
+// relative to the host across all online CPUs, and memory usage without the
+// inactive page cache.
+func ConvertStats(stats docker.StatsJSON) Stats {
+	memoryUsage := memoryUsage(stats.MemoryStats)
+
+	var memoryPercent float64
+	if stats.MemoryStats.Limit > 0 {
+		memoryPercent = float64(memoryUsage) / float64(stats.MemoryStats.Limit) * 100
+	}
+
+	return Stats{
+		CPUPercent:    cpuPercent(stats),
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   stats.MemoryStats.Limit,
+		MemoryPercent: memoryPercent,
+		Pids:          stats.PidsStats.Current,
+		PidsLimit:     stats.PidsStats.Limit,
+	}
+}
+
+func cpuPercent(stats docker.StatsJSON) float64 {
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+
+	onlineCPUs := float64(stats.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(stats.CPUStats.CPUUsage.PercpuUsage))
+	}
+
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / systemDelta * onlineCPUs * 100
+}
+
+func memoryUsage(stats docker.MemoryStats) uint64 {
+	// cgroup v1 reports total_inactive_file, cgroup v2 inactive_file.
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if cache, ok := stats.Stats[key]; ok && cache < stats.Usage {
+			return stats.Usage - cache
+		}
+	}
+	return stats.Usage
+}
@@ -0,0 +1,80 @@
+package container
+
+import (
+	"fmt"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+)
+
+type Service struct {
+	ID     string
+	Name   string
+	Image  string
+	Labels map[string]string
+	// Host is the name of the Docker host the service was listed on.
+	Host string
+}
+
+type Task struct {
+	ID        string
+	ServiceID string
+	NodeID    string
+	// Slot is the replica number of replicated services and zero for global
+	// ones.
+	Slot int
+}
+
+func ConvertServices(dockerServices []docker.Service) []Service {
+	services := make([]Service, 0, len(dockerServices))
+	for _, dockerService := range dockerServices {
+		service := Service{
+			ID:     dockerService.ID,
+			Name:   dockerService.Spec.Name,
+			Labels: dockerService.Spec.Labels,
+		}
+		if spec := dockerService.Spec.TaskTemplate.ContainerSpec; spec != nil {
+			service.Image = spec.Image
+		}
+		services = append(services, service)
+	}
+	return services
+}
+
+func ConvertTasks(dockerTasks []docker.Task) []Task {
+	tasks := make([]Task, 0, len(dockerTasks))
+	for _, dockerTask := range dockerTasks {
+		tasks = append(tasks, Task{
+			ID:        dockerTask.ID,
+			ServiceID: dockerTask.ServiceID,
+			NodeID:    dockerTask.NodeID,
+			Slot:      dockerTask.Slot,
+		})
+	}
+	return tasks
+}
+
+// TaskContainer describes a task of the service as a Container, so that
+// alerts name the service and task slot instead of the random name of the
+// task container. Tasks of global services, which have no slot, are named
+// after their node like `docker service ps` does.
+func TaskContainer(service Service, task Task) Container {
+	name := fmt.Sprintf("%s.%d", service.Name, task.Slot)
+	if task.Slot == 0 && task.NodeID != "" {
+		nodeID := task.NodeID
+		if len(nodeID) > ShortIDLen {
+			nodeID = nodeID[:ShortIDLen]
+		}
+		name = fmt.Sprintf("%s.%s", service.Name, nodeID)
+	}
+
+	return Container{
+		ID:       task.ID,
+		Name:     name,
+		Image:    service.Image,
+		Labels:   service.Labels,
+		State:    "running",
+		Host:     service.Host,
+		Service:  service.Name,
+		TaskSlot: task.Slot,
+	}
+}
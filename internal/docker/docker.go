@@ -3,26 +3,44 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"path"
 	"strconv"
+	"sync"
 )
 
-const baseURL = "http://unix"
+// unixBaseURL is the URL requests over a unix socket are sent to; the host
+// part is ignored by the dialer.
+const unixBaseURL = "http://unix"
 
 type DockerClient struct {
-	client *http.Client
-}
+	client  *http.Client
+	baseURL string
 
-func New(client *http.Client) *DockerClient {
-	return &DockerClient{client}
+	versionMu sync.Mutex
+	version   string
 }
 
+// get calls relativePath of the negotiated API version.
 func (dc *DockerClient) get(ctx context.Context, relativePath string, queryParams url.Values) (*http.Response, error) {
-	u, err := url.Parse(baseURL)
+	version, err := dc.apiVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dc.request(ctx, path.Join("/v"+version, relativePath), queryParams)
+}
+
+// request sends a GET request to the daemon. Failures are reported as
+// *APIError for error responses, and wrap ErrDaemonUnavailable or
+// ErrPermission when the daemon cannot be reached.
+func (dc *DockerClient) request(ctx context.Context, relativePath string, queryParams url.Values) (*http.Response, error) {
+	u, err := url.Parse(dc.baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call get: %w", err)
 	}
@@ -37,15 +55,23 @@ func (dc *DockerClient) get(ctx context.Context, relativePath string, queryParam
 
 	res, err := dc.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call get: %w", err)
+		switch {
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("failed to call get: %w", ctx.Err())
+		case errors.Is(err, fs.ErrPermission):
+			return nil, fmt.Errorf("failed to call get: %w: %w", ErrPermission, err)
+		default:
+			return nil, fmt.Errorf("failed to call get: %w: %w", ErrDaemonUnavailable, err)
+		}
 	}
 
 	if res.StatusCode >= 400 {
+		defer res.Body.Close()
 		body, err := io.ReadAll(res.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to call get; failed to read body: %w", err)
 		}
-		return nil, fmt.Errorf("failed to call GET: status %d, body: %s", res.StatusCode, string(body))
+		return nil, newAPIError(res, body)
 	}
 
 	return res, nil
@@ -91,57 +117,102 @@ func (dc *DockerClient) ContainerList(ctx context.Context, opts ContainerListOpt
 	defer resp.Body.Close()
 
 	var containers []Container
-	json.NewDecoder(resp.Body).Decode(&containers)
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("failed to decode container list: %w", err)
+	}
 
 	return containers, nil
 }
 
-func (dc *DockerClient) ContainerLogs(ctx context.Context, containerID string, opts ContainerLogsOptions) (io.ReadCloser, error) {
-	queryParams := url.Values{}
-	if opts.Stdout {
-		queryParams.Set("stdout", "1")
+func (dc *DockerClient) ContainerLogs(ctx context.Context, containerID string, opts ContainerLogsOptions) (*LogStream, error) {
+	queryParams, err := logsQuery(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	if opts.Stderr {
-		queryParams.Set("stderr", "1")
+	url := fmt.Sprintf("/containers/%s/logs", containerID)
+	resp, err := dc.get(ctx, url, queryParams)
+	if err != nil {
+		return nil, err
 	}
 
-	if opts.Since != "" {
-		ts, err := parseTimestamp(opts.Since)
+	return dc.logStream(ctx, resp, func(ctx context.Context) (bool, error) {
+		container, err := dc.ContainerInspect(ctx, containerID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get container list: %w", err)
+			return false, err
 		}
+		return container.Config != nil && container.Config.Tty, nil
+	})
+}
 
-		queryParams.Set("since", fmt.Sprintf("%d", ts))
+func (dc *DockerClient) ContainerInspect(ctx context.Context, containerID string) (ContainerJSON, error) {
+	endpoint := fmt.Sprintf("/containers/%s/json", containerID)
+	resp, err := dc.get(ctx, endpoint, url.Values{})
+	if err != nil {
+		return ContainerJSON{}, err
 	}
+	defer resp.Body.Close()
 
-	if opts.Until != "" {
-		ts, err := parseTimestamp(opts.Until)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get container list: %w", err)
-		}
+	var container ContainerJSON
+	if err := json.NewDecoder(resp.Body).Decode(&container); err != nil {
+		return ContainerJSON{}, fmt.Errorf("failed to decode container %s: %w", containerID, err)
+	}
+	normalizeContainerJSON(&container)
+
+	return container, nil
+}
 
-		queryParams.Set("until", fmt.Sprintf("%d", ts))
+// ContainerStats returns a single stats sample. The daemon takes two
+// measurements for it, so the CPU usage of the previous one is filled in.
+func (dc *DockerClient) ContainerStats(ctx context.Context, containerID string) (StatsJSON, error) {
+	queryParams := url.Values{}
+	queryParams.Set("stream", "0")
+
+	endpoint := fmt.Sprintf("/containers/%s/stats", containerID)
+	resp, err := dc.get(ctx, endpoint, queryParams)
+	if err != nil {
+		return StatsJSON{}, err
 	}
+	defer resp.Body.Close()
 
-	if opts.Timestamp {
-		queryParams.Set("timestamps", "1")
+	var stats StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return StatsJSON{}, fmt.Errorf("failed to decode stats of container %s: %w", containerID, err)
 	}
 
-	if opts.Follow {
-		queryParams.Set("follow", "1")
+	return stats, nil
+}
+
+// DiskUsage returns the space used by images, volumes and the build cache.
+// Computing volume sizes can take a while on hosts with many volumes.
+func (dc *DockerClient) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	resp, err := dc.get(ctx, "/system/df", url.Values{})
+	if err != nil {
+		return DiskUsage{}, err
 	}
+	defer resp.Body.Close()
 
-	if opts.Tail != "" {
-		queryParams.Set("tail", opts.Tail)
+	var usage DiskUsage
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to decode disk usage: %w", err)
 	}
 
-	url := fmt.Sprintf("/containers/%s/logs", containerID)
-	resp, err := dc.get(ctx, url, queryParams)
+	return usage, nil
+}
+
+func (dc *DockerClient) Info(ctx context.Context) (Info, error) {
+	resp, err := dc.get(ctx, "/info", url.Values{})
 	if err != nil {
-		return nil, err
+		return Info{}, err
+	}
+	defer resp.Body.Close()
+
+	var info Info
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return Info{}, fmt.Errorf("failed to decode info: %w", err)
 	}
-	return resp.Body, nil
+
+	return info, nil
 }
 
 func (dc *DockerClient) Ping(ctx context.Context) (string, error) {
@@ -149,6 +220,7 @@ func (dc *DockerClient) Ping(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -163,3 +235,50 @@ func (dc *DockerClient) Close() {
 		dc.client.CloseIdleConnections()
 	}
 }
+
+func logsQuery(opts ContainerLogsOptions) (url.Values, error) {
+	queryParams := url.Values{}
+	if opts.Stdout {
+		queryParams.Set("stdout", "1")
+	}
+
+	if opts.Stderr {
+		queryParams.Set("stderr", "1")
+	}
+
+	if opts.Since != "" {
+		ts, err := parseTimestamp(opts.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since: %w", err)
+		}
+
+		queryParams.Set("since", formatTimestamp(ts))
+	}
+
+	if opts.Until != "" {
+		ts, err := parseTimestamp(opts.Until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until: %w", err)
+		}
+
+		queryParams.Set("until", formatTimestamp(ts))
+	}
+
+	if opts.Timestamp {
+		queryParams.Set("timestamps", "1")
+	}
+
+	if opts.Follow {
+		queryParams.Set("follow", "1")
+	}
+
+	if opts.Tail != "" {
+		queryParams.Set("tail", opts.Tail)
+	}
+
+	if opts.Details {
+		queryParams.Set("details", "1")
+	}
+
+	return queryParams, nil
+}
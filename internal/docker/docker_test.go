@@ -0,0 +1,260 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestClient returns a client of a daemon served by handler.
+func newTestClient(t *testing.T, handler http.Handler) *DockerClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewFromHost(HostOptions{Host: "tcp://" + strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestDockerClient_errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		want       error
+		wantStatus int
+		wantMsg    string
+	}{
+		{
+			name:       "not found",
+			status:     http.StatusNotFound,
+			body:       `{"message":"No such container: app"}`,
+			want:       ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantMsg:    "No such container: app",
+		},
+		{
+			name:       "unauthorized",
+			status:     http.StatusUnauthorized,
+			body:       `{"message":"authorization denied"}`,
+			want:       ErrPermission,
+			wantStatus: http.StatusUnauthorized,
+			wantMsg:    "authorization denied",
+		},
+		{
+			name:       "forbidden",
+			status:     http.StatusForbidden,
+			body:       `{"message":"authorization denied by plugin"}`,
+			want:       ErrPermission,
+			wantStatus: http.StatusForbidden,
+			wantMsg:    "authorization denied by plugin",
+		},
+		{
+			name:       "conflict",
+			status:     http.StatusConflict,
+			body:       `{"message":"container is paused"}`,
+			want:       ErrConflict,
+			wantStatus: http.StatusConflict,
+			wantMsg:    "container is paused",
+		},
+		{
+			name:       "service unavailable",
+			status:     http.StatusServiceUnavailable,
+			body:       `{"message":"daemon is shutting down"}`,
+			want:       ErrDaemonUnavailable,
+			wantStatus: http.StatusServiceUnavailable,
+			wantMsg:    "daemon is shutting down",
+		},
+		{
+			name:       "internal server error",
+			status:     http.StatusInternalServerError,
+			body:       "plain text failure\n",
+			wantStatus: http.StatusInternalServerError,
+			wantMsg:    "plain text failure",
+		},
+	}
+
+	sentinels := []error{ErrNotFound, ErrPermission, ErrConflict, ErrDaemonUnavailable}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/version" {
+					w.Write([]byte(`{"ApiVersion":"1.45"}`))
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+
+			_, err := client.ContainerInspect(context.Background(), "app")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, apiErr.StatusCode)
+			}
+			if apiErr.Message != tt.wantMsg {
+				t.Errorf("expected message %q, got %q", tt.wantMsg, apiErr.Message)
+			}
+			if apiErr.Method != http.MethodGet || apiErr.Path != "/v1.45/containers/app/json" {
+				t.Errorf("expected GET /v1.45/containers/app/json, got %s %s", apiErr.Method, apiErr.Path)
+			}
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDockerClient_unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	host := "tcp://" + strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	client, err := NewFromHost(HostOptions{Host: host})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	_, err = client.ContainerList(context.Background(), ContainerListOptions{})
+	if !errors.Is(err, ErrDaemonUnavailable) {
+		t.Errorf("expected ErrDaemonUnavailable, got %v", err)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("expected no *APIError for a daemon that cannot be reached, got %v", apiErr)
+	}
+}
+
+func TestDockerClient_canceled(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ApiVersion":"1.45"}`))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.ServerVersion(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if errors.Is(err, ErrDaemonUnavailable) {
+		t.Errorf("expected a canceled request not to be reported as ErrDaemonUnavailable, got %v", err)
+	}
+}
+
+func TestDockerClient_apiVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverVersion string
+		want          string
+	}{
+		{name: "older daemon", serverVersion: "1.41", want: "1.41"},
+		{name: "oldest daemon", serverVersion: "1.24", want: "1.24"},
+		{name: "same version", serverVersion: MaxAPIVersion, want: MaxAPIVersion},
+		{name: "newer daemon", serverVersion: "1.47", want: MaxAPIVersion},
+		{name: "newer minor with more digits", serverVersion: "1.100", want: MaxAPIVersion},
+		{name: "no version", serverVersion: "", want: MaxAPIVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var paths []string
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				paths = append(paths, r.URL.Path)
+				mu.Unlock()
+
+				if r.URL.Path == "/version" {
+					w.Write([]byte(`{"ApiVersion":"` + tt.serverVersion + `"}`))
+					return
+				}
+				w.Write([]byte("OK"))
+			}))
+
+			for range 2 {
+				if _, err := client.Ping(context.Background()); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			// The version is negotiated once.
+			want := []string{"/version", "/v" + tt.want + "/_ping", "/v" + tt.want + "/_ping"}
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(paths, " ") != strings.Join(want, " ") {
+				t.Errorf("expected requests %v, got %v", want, paths)
+			}
+		})
+	}
+}
+
+func TestDockerClient_apiVersionRetry(t *testing.T) {
+	var mu sync.Mutex
+	failures := 1
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.URL.Path == "/version" && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/version" {
+			w.Write([]byte(`{"ApiVersion":"1.41"}`))
+			return
+		}
+		if r.URL.Path != "/v1.41/_ping" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+
+	if _, err := client.Ping(context.Background()); !errors.Is(err, ErrDaemonUnavailable) {
+		t.Fatalf("expected the failed negotiation to report ErrDaemonUnavailable, got %v", err)
+	}
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Errorf("expected the negotiation to be retried, got %v", err)
+	}
+}
+
+func TestContainerList_decodeError(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/version" {
+			w.Write([]byte(`{"ApiVersion":"1.45"}`))
+			return
+		}
+		w.Write([]byte(`{"Id":"not a list"}`))
+	}))
+
+	_, err := client.ContainerList(context.Background(), ContainerListOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to decode container list") {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Errorf("expected no *APIError for a malformed response, got %v", apiErr)
+	}
+}
@@ -0,0 +1,74 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Events subscribes to the daemon event stream. Decoded messages are sent on
+// the first channel; the second channel receives a single error when the
+// stream ends, either because the daemon closed it or the context was canceled.
+func (dc *DockerClient) Events(ctx context.Context, opts EventsOptions) (<-chan Event, <-chan error) {
+	messages := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(messages)
+
+		queryParams := url.Values{}
+
+		if opts.Since != "" {
+			queryParams.Set("since", opts.Since)
+		}
+
+		if opts.Until != "" {
+			queryParams.Set("until", opts.Until)
+		}
+
+		if opts.Filters.Len() > 0 {
+			filterJSON, err := opts.Filters.Encode()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			queryParams.Set("filters", filterJSON)
+		}
+
+		resp, err := dc.get(ctx, "/events", queryParams)
+		if err != nil {
+			errs <- fmt.Errorf("failed to subscribe to events: %w", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event Event
+			if err := decoder.Decode(&event); err != nil {
+				if ctx.Err() != nil {
+					errs <- ctx.Err()
+				} else if errors.Is(err, io.EOF) {
+					errs <- fmt.Errorf("event stream closed by daemon: %w", err)
+				} else {
+					errs <- fmt.Errorf("failed to decode event: %w", err)
+				}
+				return
+			}
+			normalizeEvent(&event)
+
+			select {
+			case messages <- event:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return messages, errs
+}
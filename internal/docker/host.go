@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultHost   = "unix://" + defaultSocket
+	defaultSocket = "/var/run/docker.sock"
+
+	defaultHTTPPort = "2375"
+	defaultTLSPort  = "2376"
+)
+
+// HostOptions describe how to reach the daemon, like the DOCKER_HOST,
+// DOCKER_TLS_VERIFY and DOCKER_CERT_PATH variables of the docker CLI.
+type HostOptions struct {
+	// Host is a unix:// or tcp:// address; empty means DefaultHost.
+	Host string
+	// TLSVerify enables mutual TLS with the certificates in CertPath.
+	TLSVerify bool
+	// CertPath is the directory holding ca.pem, cert.pem and key.pem.
+	CertPath string
+}
+
+// NewFromHost creates a client for the daemon at opts.Host.
+func NewFromHost(opts HostOptions) (*DockerClient, error) {
+	host := opts.Host
+	if host == "" {
+		host = DefaultHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+
+	dialer := &net.Dialer{}
+	transport := &http.Transport{}
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		if socket == "" {
+			return nil, fmt.Errorf("invalid docker host %q: missing socket path", host)
+		}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		return &DockerClient{client: &http.Client{Transport: transport}, baseURL: unixBaseURL}, nil
+
+	case "tcp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid docker host %q: missing address", host)
+		}
+
+		scheme, port := "http", defaultHTTPPort
+		if opts.TLSVerify {
+			tlsConfig, err := loadTLSConfig(opts.CertPath)
+			if err != nil {
+				return nil, err
+			}
+			transport.TLSClientConfig = tlsConfig
+			scheme, port = "https", defaultTLSPort
+		}
+
+		address := u.Host
+		if u.Port() == "" {
+			address = net.JoinHostPort(u.Hostname(), port)
+		}
+		transport.DialContext = dialer.DialContext
+
+		return &DockerClient{
+			client:  &http.Client{Transport: transport},
+			baseURL: fmt.Sprintf("%s://%s", scheme, address),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q", u.Scheme)
+	}
+}
+
+func loadTLSConfig(certPath string) (*tls.Config, error) {
+	if certPath == "" {
+		return nil, errors.New("TLS verification requires a certificate path")
+	}
+
+	ca, err := os.ReadFile(filepath.Join(certPath, "ca.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse CA certificate in %s", certPath)
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(certPath, "cert.pem"), filepath.Join(certPath, "key.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,373 @@
+package docker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCerts is a CA and a certificate signed by it, valid for 127.0.0.1.
+type testCerts struct {
+	caPEM   []byte
+	certPEM []byte
+	keyPEM  []byte
+	pool    *x509.CertPool
+}
+
+func newTestCerts(t *testing.T) *testCerts {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	return &testCerts{
+		caPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		pool:    pool,
+	}
+}
+
+// write writes the given files of the certificates to a new directory, like
+// DOCKER_CERT_PATH, and returns it.
+func (c *testCerts) write(t *testing.T, files ...string) string {
+	t.Helper()
+
+	contents := map[string][]byte{"ca.pem": c.caPEM, "cert.pem": c.certPEM, "key.pem": c.keyPEM}
+	dir := t.TempDir()
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), contents[file], 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", file, err)
+		}
+	}
+	return dir
+}
+
+func TestNewFromHost(t *testing.T) {
+	certs := newTestCerts(t)
+	certPath := certs.write(t, "ca.pem", "cert.pem", "key.pem")
+
+	tests := []struct {
+		name        string
+		opts        HostOptions
+		wantBaseURL string
+		wantTLS     bool
+		wantErr     string
+	}{
+		{
+			name:        "empty host",
+			opts:        HostOptions{},
+			wantBaseURL: unixBaseURL,
+		},
+		{
+			name:        "unix socket",
+			opts:        HostOptions{Host: "unix:///run/user/1000/docker.sock"},
+			wantBaseURL: unixBaseURL,
+		},
+		{
+			name:    "unix without a socket path",
+			opts:    HostOptions{Host: "unix://"},
+			wantErr: "missing socket path",
+		},
+		{
+			name:        "tcp with a port",
+			opts:        HostOptions{Host: "tcp://10.0.0.5:2377"},
+			wantBaseURL: "http://10.0.0.5:2377",
+		},
+		{
+			name:        "tcp without a port",
+			opts:        HostOptions{Host: "tcp://docker.example.com"},
+			wantBaseURL: "http://docker.example.com:2375",
+		},
+		{
+			name:    "tcp without an address",
+			opts:    HostOptions{Host: "tcp://"},
+			wantErr: "missing address",
+		},
+		{
+			name:        "tcp with TLS and a port",
+			opts:        HostOptions{Host: "tcp://10.0.0.5:2377", TLSVerify: true, CertPath: certPath},
+			wantBaseURL: "https://10.0.0.5:2377",
+			wantTLS:     true,
+		},
+		{
+			name:        "tcp with TLS without a port",
+			opts:        HostOptions{Host: "tcp://docker.example.com", TLSVerify: true, CertPath: certPath},
+			wantBaseURL: "https://docker.example.com:2376",
+			wantTLS:     true,
+		},
+		{
+			name:        "cert path without TLS",
+			opts:        HostOptions{Host: "tcp://docker.example.com", CertPath: certPath},
+			wantBaseURL: "http://docker.example.com:2375",
+		},
+		{
+			name:    "TLS without a cert path",
+			opts:    HostOptions{Host: "tcp://docker.example.com", TLSVerify: true},
+			wantErr: "requires a certificate path",
+		},
+		{
+			name:    "unsupported scheme",
+			opts:    HostOptions{Host: "ssh://user@docker.example.com"},
+			wantErr: `unsupported docker host scheme "ssh"`,
+		},
+		{
+			name:    "invalid address",
+			opts:    HostOptions{Host: "tcp://[::1"},
+			wantErr: "invalid docker host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewFromHost(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if client.baseURL != tt.wantBaseURL {
+				t.Errorf("expected base URL %q, got %q", tt.wantBaseURL, client.baseURL)
+			}
+
+			transport := client.client.Transport.(*http.Transport)
+			if gotTLS := transport.TLSClientConfig != nil; gotTLS != tt.wantTLS {
+				t.Errorf("expected TLS %v, got %v", tt.wantTLS, gotTLS)
+			}
+		})
+	}
+}
+
+func TestNewFromHost_unixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets not supported: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version":"26.1.0","ApiVersion":"1.45"}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := NewFromHost(HostOptions{Host: "unix://" + socket})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	version, err := client.ServerVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version.APIVersion != "1.45" {
+		t.Errorf("expected API version 1.45, got %q", version.APIVersion)
+	}
+}
+
+func TestNewFromHost_tls(t *testing.T) {
+	certs := newTestCerts(t)
+
+	serverCert, err := tls.X509KeyPair(certs.certPEM, certs.keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version":"26.1.0","ApiVersion":"1.45"}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    certs.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	host := "tcp://" + strings.TrimPrefix(server.URL, "https://")
+
+	client, err := NewFromHost(HostOptions{Host: host, TLSVerify: true, CertPath: certs.write(t, "ca.pem", "cert.pem", "key.pem")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.ServerVersion(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Without TLS the server answers with an error to plain HTTP.
+	client, err = NewFromHost(HostOptions{Host: host})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.ServerVersion(context.Background()); err == nil {
+		t.Error("expected plain HTTP to a TLS daemon to fail")
+	}
+}
+
+func TestNewFromHost_canceledDial(t *testing.T) {
+	for _, host := range []string{"unix:///var/run/docker.sock", "tcp://127.0.0.1:2375"} {
+		t.Run(host, func(t *testing.T) {
+			client, err := NewFromHost(HostOptions{Host: host})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			transport := client.client.Transport.(*http.Transport)
+			conn, err := transport.DialContext(ctx, "tcp", "127.0.0.1:2375")
+			if err == nil {
+				conn.Close()
+				t.Fatal("expected a canceled dial to fail")
+			}
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	certs := newTestCerts(t)
+
+	tests := []struct {
+		name     string
+		certPath func(t *testing.T) string
+		wantErr  string
+	}{
+		{
+			name:     "all files",
+			certPath: func(t *testing.T) string { return certs.write(t, "ca.pem", "cert.pem", "key.pem") },
+		},
+		{
+			name:     "no cert path",
+			certPath: func(t *testing.T) string { return "" },
+			wantErr:  "requires a certificate path",
+		},
+		{
+			name:     "missing directory",
+			certPath: func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing") },
+			wantErr:  "failed to read CA certificate",
+		},
+		{
+			name:     "missing CA",
+			certPath: func(t *testing.T) string { return certs.write(t, "cert.pem", "key.pem") },
+			wantErr:  "failed to read CA certificate",
+		},
+		{
+			name: "invalid CA",
+			certPath: func(t *testing.T) string {
+				dir := certs.write(t, "cert.pem", "key.pem")
+				if err := os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("not a certificate"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				return dir
+			},
+			wantErr: "failed to parse CA certificate",
+		},
+		{
+			name:     "missing client certificate",
+			certPath: func(t *testing.T) string { return certs.write(t, "ca.pem", "key.pem") },
+			wantErr:  "failed to load client certificate",
+		},
+		{
+			name:     "missing client key",
+			certPath: func(t *testing.T) string { return certs.write(t, "ca.pem", "cert.pem") },
+			wantErr:  "failed to load client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := loadTLSConfig(tt.certPath(t))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(config.Certificates) != 1 {
+				t.Errorf("expected 1 client certificate, got %d", len(config.Certificates))
+			}
+			if config.RootCAs == nil {
+				t.Error("expected the CA in the root pool")
+			}
+			if config.MinVersion != tls.VersionTLS12 {
+				t.Errorf("expected TLS 1.2 at least, got %x", config.MinVersion)
+			}
+		})
+	}
+}
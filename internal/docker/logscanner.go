@@ -0,0 +1,246 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type StreamType byte
+
+const (
+	StreamStdin     StreamType = 0
+	StreamStdout    StreamType = 1
+	StreamStderr    StreamType = 2
+	StreamSystemErr StreamType = 3
+
+	// StreamTTY marks lines read from a raw, non-multiplexed stream. This is
+	// what the daemon sends for containers started with a TTY.
+	StreamTTY StreamType = 255
+)
+
+const frameHeaderLen = 8
+
+func (s StreamType) String() string {
+	switch s {
+	case StreamStdin:
+		return "stdin"
+	case StreamStdout:
+		return "stdout"
+	case StreamStderr:
+		return "stderr"
+	case StreamSystemErr:
+		return "system"
+	case StreamTTY:
+		return "tty"
+	default:
+		return fmt.Sprintf("stream(%d)", byte(s))
+	}
+}
+
+// readChunkSize bounds the bytes read from the stream at a time, so that a
+// large frame or a raw stream without newlines is not read into memory at
+// once.
+const readChunkSize = 32 * 1024
+
+type logLine struct {
+	stream    StreamType
+	data      []byte
+	truncated bool
+}
+
+// partialLine is the start of a line whose newline has not been read yet.
+type partialLine struct {
+	data      []byte
+	truncated bool
+}
+
+// add appends data, keeping at most max bytes when max is positive.
+func (p *partialLine) add(data []byte, max int) {
+	if max > 0 && len(p.data)+len(data) > max {
+		data = data[:max-len(p.data)]
+		p.truncated = true
+	}
+	p.data = append(p.data, data...)
+}
+
+// LogScanner splits a container log stream into lines. Multiplexed streams
+// are decoded frame by frame using the length from the 8-byte frame header,
+// so payloads that contain newlines or header-like bytes are handled
+// correctly. Lines split across frames are reassembled per stream. Whether a
+// stream is multiplexed is told by the daemon, see LogStream.
+//
+// The stream is read in chunks of at most readChunkSize bytes, and lines are
+// cut to the length set with SetMaxLineLength, so memory use does not depend
+// on how much a container logs.
+type LogScanner struct {
+	r         *bufio.Reader
+	raw       bool
+	maxLine   int
+	pending   map[StreamType]*partialLine
+	queue     []logLine
+	chunk     []byte
+	frame     StreamType
+	frameLeft int
+	line      logLine
+	err       error
+}
+
+// NewLogScanner returns a scanner of r, a multiplexed stream or the raw
+// output of a container with a TTY.
+func NewLogScanner(r io.Reader, multiplexed bool) *LogScanner {
+	return &LogScanner{
+		r:       bufio.NewReader(r),
+		raw:     !multiplexed,
+		pending: make(map[StreamType]*partialLine),
+		chunk:   make([]byte, readChunkSize),
+	}
+}
+
+// SetMaxLineLength cuts lines longer than n bytes to their first n bytes, see
+// Truncated. Zero, the default, keeps lines whole. It must be called before
+// the first call to Scan.
+func (s *LogScanner) SetMaxLineLength(n int) {
+	s.maxLine = n
+}
+
+// Scan advances to the next line. It returns false when the stream ends or
+// an error occurs; Err reports the error, if any.
+func (s *LogScanner) Scan() bool {
+	// Queued lines may point into the chunk, so it is only refilled once they
+	// are consumed.
+	for len(s.queue) == 0 {
+		if s.err != nil {
+			return false
+		}
+
+		if s.raw {
+			s.readRaw()
+		} else {
+			s.readFrame()
+		}
+	}
+
+	s.line = s.queue[0]
+	s.queue = s.queue[1:]
+	return true
+}
+
+// Line returns the most recent line without its trailing newline. The
+// underlying array may be reused by the next call to Scan.
+func (s *LogScanner) Line() []byte {
+	return s.line.data
+}
+
+// Stream returns the stream the most recent line was written to.
+func (s *LogScanner) Stream() StreamType {
+	return s.line.stream
+}
+
+// Truncated reports whether the most recent line was cut to the maximum line
+// length.
+func (s *LogScanner) Truncated() bool {
+	return s.line.truncated
+}
+
+func (s *LogScanner) Err() error {
+	if errors.Is(s.err, io.EOF) {
+		return nil
+	}
+	return s.err
+}
+
+func (s *LogScanner) readRaw() {
+	n, err := s.r.Read(s.chunk)
+	s.write(StreamTTY, s.chunk[:n])
+	if err != nil {
+		s.fail(err)
+	}
+}
+
+// readFrame reads the next chunk of the current frame, or the header of the
+// next frame.
+func (s *LogScanner) readFrame() {
+	if s.frameLeft == 0 {
+		var header [frameHeaderLen]byte
+		if _, err := io.ReadFull(s.r, header[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = fmt.Errorf("truncated log frame header: %w", err)
+			}
+			s.fail(err)
+			return
+		}
+
+		s.frame = StreamType(header[0])
+		s.frameLeft = int(binary.BigEndian.Uint32(header[4:]))
+		if s.frameLeft == 0 {
+			return
+		}
+	}
+
+	payload := s.chunk[:min(s.frameLeft, len(s.chunk))]
+	if _, err := io.ReadFull(s.r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		s.fail(fmt.Errorf("truncated log frame: %w", err))
+		return
+	}
+	s.frameLeft -= len(payload)
+
+	if s.frame == StreamSystemErr {
+		s.fail(fmt.Errorf("error from daemon in log stream: %s", payload))
+		return
+	}
+
+	s.write(s.frame, payload)
+}
+
+// write queues the complete lines of data and keeps the rest until the next
+// newline of the stream.
+func (s *LogScanner) write(stream StreamType, data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			p, ok := s.pending[stream]
+			if !ok {
+				p = &partialLine{}
+				s.pending[stream] = p
+			}
+			p.add(data, s.maxLine)
+			return
+		}
+
+		line := logLine{stream: stream, data: data[:i]}
+		data = data[i+1:]
+
+		if p, ok := s.pending[stream]; ok {
+			// The queued line keeps the buffer, the next partial line
+			// gets a new one.
+			delete(s.pending, stream)
+			p.add(line.data, s.maxLine)
+			line.data, line.truncated = p.data, p.truncated
+		} else if s.maxLine > 0 && len(line.data) > s.maxLine {
+			line.data, line.truncated = line.data[:s.maxLine], true
+		}
+
+		line.data = bytes.TrimSuffix(line.data, []byte{'\r'})
+		s.queue = append(s.queue, line)
+	}
+}
+
+// fail records err and flushes incomplete lines, so the last line of a stream
+// without a trailing newline is not lost.
+func (s *LogScanner) fail(err error) {
+	s.err = err
+
+	for _, stream := range []StreamType{StreamStdout, StreamStderr, StreamStdin, StreamTTY} {
+		if p, ok := s.pending[stream]; ok {
+			s.queue = append(s.queue, logLine{stream: stream, data: p.data, truncated: p.truncated})
+			delete(s.pending, stream)
+		}
+	}
+}
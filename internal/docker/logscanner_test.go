@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func frame(stream StreamType, payload string) []byte {
+	header := make([]byte, frameHeaderLen)
+	header[0] = byte(stream)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestLogScanner(t *testing.T) {
+	long := strings.Repeat("x", 3*readChunkSize)
+
+	type line struct {
+		stream    StreamType
+		data      string
+		truncated bool
+	}
+
+	tests := []struct {
+		name string
+		logs []byte
+		// raw is set for the output of a container with a TTY.
+		raw     bool
+		maxLine int
+		want    []line
+	}{
+		{
+			name: "raw stream",
+			logs: []byte("one\r\ntwo\nthree"),
+			raw:  true,
+			want: []line{{StreamTTY, "one", false}, {StreamTTY, "two", false}, {StreamTTY, "three", false}},
+		},
+		{
+			name:    "raw stream with a long line",
+			logs:    []byte("short\n" + long + "\nnext\n"),
+			raw:     true,
+			maxLine: 8,
+			want:    []line{{StreamTTY, "short", false}, {StreamTTY, "xxxxxxxx", true}, {StreamTTY, "next", false}},
+		},
+		{
+			name: "line split across frames",
+			logs: append(frame(StreamStdout, "hel"), frame(StreamStdout, "lo\nworld\n")...),
+			want: []line{{StreamStdout, "hello", false}, {StreamStdout, "world", false}},
+		},
+		{
+			name:    "long line split across frames",
+			logs:    bytes.Join([][]byte{frame(StreamStderr, "abcdef"), frame(StreamStderr, "ghij\nok\n")}, nil),
+			maxLine: 8,
+			want:    []line{{StreamStderr, "abcdefgh", true}, {StreamStderr, "ok", false}},
+		},
+		{
+			name: "frame larger than a chunk",
+			logs: frame(StreamStdout, long+"\nend\n"),
+			want: []line{{StreamStdout, long, false}, {StreamStdout, "end", false}},
+		},
+		{
+			name:    "long line in a frame larger than a chunk",
+			logs:    frame(StreamStdout, long+"\nend"),
+			maxLine: readChunkSize + 1,
+			want:    []line{{StreamStdout, long[:readChunkSize+1], true}, {StreamStdout, "end", false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewLogScanner(bytes.NewReader(tt.logs), !tt.raw)
+			scanner.SetMaxLineLength(tt.maxLine)
+
+			var got []line
+			for scanner.Scan() {
+				got = append(got, line{scanner.Stream(), string(scanner.Line()), scanner.Truncated()})
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d lines, got %d", len(tt.want), len(got))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d: expected %s %.20q (truncated %v), got %s %.20q (truncated %v)",
+						i, tt.want[i].stream, tt.want[i].data, tt.want[i].truncated, got[i].stream, got[i].data, got[i].truncated)
+				}
+			}
+		})
+	}
+}
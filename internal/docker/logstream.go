@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Media types of log responses.
+const (
+	MediaTypeRawStream         = "application/vnd.docker.raw-stream"
+	MediaTypeMultiplexedStream = "application/vnd.docker.multiplexed-stream"
+)
+
+// mediaTypeAPIVersion is the first API version whose log responses name the
+// multiplexed media type. Older daemons send MediaTypeRawStream either way.
+const mediaTypeAPIVersion = "1.42"
+
+// LogStream is the body of a log response.
+type LogStream struct {
+	io.ReadCloser
+	// Multiplexed is set for a stream of frames with headers, and unset for
+	// the raw output of a container with a TTY.
+	Multiplexed bool
+}
+
+// logStream returns the body of a log response, multiplexed or not as its
+// Content-Type tells. Where that does not tell, tty is asked whether the
+// logs are those of a container with a TTY.
+func (dc *DockerClient) logStream(ctx context.Context, resp *http.Response, tty func(context.Context) (bool, error)) (*LogStream, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	if mediaType == MediaTypeMultiplexedStream {
+		return &LogStream{ReadCloser: resp.Body, Multiplexed: true}, nil
+	}
+
+	if mediaType == MediaTypeRawStream {
+		version, err := dc.apiVersion(ctx)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if compareVersions(version, mediaTypeAPIVersion) >= 0 {
+			return &LogStream{ReadCloser: resp.Body}, nil
+		}
+	}
+
+	isTTY, err := tty(ctx)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return &LogStream{ReadCloser: resp.Body, Multiplexed: !isTTY}, nil
+}
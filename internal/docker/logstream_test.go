@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestContainerLogs_multiplexed(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiVersion  string
+		contentType string
+		tty         bool
+		want        bool
+	}{
+		{
+			name:        "multiplexed stream",
+			apiVersion:  "1.45",
+			contentType: MediaTypeMultiplexedStream,
+			want:        true,
+		},
+		{
+			name:        "raw stream",
+			apiVersion:  "1.45",
+			contentType: MediaTypeRawStream,
+			tty:         true,
+			want:        false,
+		},
+		{
+			name:        "raw stream of an old daemon without a TTY",
+			apiVersion:  "1.41",
+			contentType: MediaTypeRawStream,
+			want:        true,
+		},
+		{
+			name:        "raw stream of an old daemon with a TTY",
+			apiVersion:  "1.41",
+			contentType: MediaTypeRawStream,
+			tty:         true,
+			want:        false,
+		},
+		{
+			name:        "media type with parameters",
+			apiVersion:  "1.45",
+			contentType: MediaTypeMultiplexedStream + "; charset=utf-8",
+			want:        true,
+		},
+		{
+			name:       "no media type",
+			apiVersion: "1.45",
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/version":
+					w.Write([]byte(`{"ApiVersion":"` + tt.apiVersion + `"}`))
+				case strings.HasSuffix(r.URL.Path, "/containers/app/json"):
+					if tt.tty {
+						w.Write([]byte(`{"Id":"app","Config":{"Tty":true}}`))
+					} else {
+						w.Write([]byte(`{"Id":"app","Config":{"Tty":false}}`))
+					}
+				case strings.HasSuffix(r.URL.Path, "/containers/app/logs"):
+					if tt.contentType != "" {
+						w.Header().Set("Content-Type", tt.contentType)
+					}
+					w.Write([]byte("hello\n"))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			logs, err := client.ContainerLogs(context.Background(), "app", ContainerLogsOptions{Stdout: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer logs.Close()
+
+			if logs.Multiplexed != tt.want {
+				t.Errorf("expected multiplexed %v, got %v", tt.want, logs.Multiplexed)
+			}
+		})
+	}
+}
@@ -18,4 +18,21 @@ type ContainerLogsOptions struct {
 	Until     string
 	Timestamp bool
 	Tail      string
+	// Details adds the extra attributes of the log driver, such as the task
+	// ID of service logs, in front of every line.
+	Details bool
+}
+
+type ServiceListOptions struct {
+	Filters Filters
+}
+
+type TaskListOptions struct {
+	Filters Filters
+}
+
+type EventsOptions struct {
+	Since   string
+	Until   string
+	Filters Filters
 }
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const podmanRootSocket = "/run/podman/podman.sock"
+
+// PodmanSockets returns the sockets of the Docker-compatible Podman API
+// service: the rootful one and, when XDG_RUNTIME_DIR is set, the rootless one
+// of the current user.
+func PodmanSockets() []string {
+	sockets := []string{podmanRootSocket}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return sockets
+}
+
+// DetectHost returns DefaultHost when the Docker socket exists, and otherwise
+// the first Podman socket found. Without any socket DefaultHost is returned,
+// so that the error names the usual Docker socket.
+func DetectHost() string {
+	return detectHost(append([]string{defaultSocket}, PodmanSockets()...))
+}
+
+func detectHost(sockets []string) string {
+	for _, socket := range sockets {
+		if info, err := os.Stat(socket); err == nil && !info.IsDir() {
+			return "unix://" + socket
+		}
+	}
+	return DefaultHost
+}
+
+// normalizeEvent rewrites the fields in which Podman events differ from
+// Docker ones:
+//   - container exits are reported as "died" rather than "die";
+//   - the exit code is in the containerExitCode attribute;
+//   - health changes are a bare "health_status" action with the status in the
+//     health_status attribute, instead of "health_status: <status>".
+func normalizeEvent(event *Event) {
+	attributes := event.Actor.Attributes
+
+	switch event.Action {
+	case "died":
+		event.Action = "die"
+	case "health_status":
+		if status := attributes["health_status"]; status != "" {
+			event.Action = "health_status: " + status
+			delete(attributes, "health_status")
+		}
+	}
+
+	if exitCode, ok := attributes["containerExitCode"]; ok {
+		if _, ok := attributes["exitCode"]; !ok {
+			attributes["exitCode"] = exitCode
+		}
+		delete(attributes, "containerExitCode")
+	}
+}
+
+// normalizeContainerJSON fills in the health of containers inspected through
+// older Podman versions, which report it as State.Healthcheck.
+func normalizeContainerJSON(container *ContainerJSON) {
+	if container.State != nil && container.State.Health == nil {
+		container.State.Health = container.State.Healthcheck
+	}
+	if container.State != nil {
+		container.State.Healthcheck = nil
+	}
+}
@@ -0,0 +1,252 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	podmanWebID    = "3f5d8a1b2c4e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+	podmanWorkerID = "9b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c"
+)
+
+// newPodmanServer serves the synthetic responses of the Docker-compatible API
+// of Podman in testdata/podman, see the README there.
+func newPodmanServer(t *testing.T) *DockerClient {
+	t.Helper()
+
+	routes := map[string]string{
+		"/version":               "version.json",
+		"/v1.41/info":            "info.json",
+		"/v1.41/containers/json": "containers.json",
+		"/v1.41/containers/" + podmanWorkerID + "/json": "container_inspect.json",
+		"/v1.41/containers/" + podmanWorkerID + "/logs": "logs.bin",
+		"/v1.41/events": "events.jsonl",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, ok := routes[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"cause":"no such container","message":"no container with name or ID \"missing\" found: no such container","response":404}`))
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", "podman", file))
+		if err != nil {
+			t.Errorf("failed to read fixture: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if filepath.Ext(file) == ".bin" {
+			w.Header().Set("Content-Type", MediaTypeRawStream)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewFromHost(HostOptions{Host: "tcp://" + strings.TrimPrefix(server.URL, "http://")})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestPodman_ServerVersion(t *testing.T) {
+	client := newPodmanServer(t)
+
+	info, err := client.Info(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Name != "podman-host-1" || info.ServerVersion != "4.9.4" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	version, err := client.apiVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "1.41" {
+		t.Errorf("expected the API version of the daemon, got %s", version)
+	}
+}
+
+func TestPodman_ContainerList(t *testing.T) {
+	client := newPodmanServer(t)
+
+	containers, err := client.ContainerList(context.Background(), ContainerListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(containers))
+	}
+
+	web := containers[0]
+	if web.ID != podmanWebID || web.State != "running" || web.Labels["com.andvarfolomeev.dockernotifier.enable"] != "true" {
+		t.Errorf("unexpected container: %+v", web)
+	}
+
+	// Podman reports names with and without the leading slash.
+	if names := containers[1].Names; len(names) != 1 || names[0] != "worker" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestPodman_ContainerInspect(t *testing.T) {
+	client := newPodmanServer(t)
+
+	container, err := client.ContainerInspect(context.Background(), podmanWorkerID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if container.RestartCount != 2 || container.Config == nil || container.Config.Image != "localhost/worker:latest" {
+		t.Errorf("unexpected container: %+v", container)
+	}
+
+	health := container.State.Health
+	if health == nil {
+		t.Fatal("expected health from State.Healthcheck")
+	}
+	if health.Status != "unhealthy" || health.FailingStreak != 3 || len(health.Log) != 2 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+	if container.State.Healthcheck != nil {
+		t.Error("expected State.Healthcheck to be folded into State.Health")
+	}
+
+	_, err = client.ContainerInspect(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestPodman_Events(t *testing.T) {
+	client := newPodmanServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, errs := client.Events(ctx, EventsOptions{})
+
+	var events []Event
+	for event := range messages {
+		events = append(events, event)
+	}
+	if err := <-errs; err == nil || !strings.Contains(err.Error(), "closed by daemon") {
+		t.Errorf("expected the stream to be closed by the daemon, got %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+
+	testCases := []struct {
+		action     string
+		attributes map[string]string
+	}{
+		{action: "start", attributes: map[string]string{"exitCode": "0"}},
+		{action: "health_status: unhealthy", attributes: map[string]string{"exitCode": "0"}},
+		{action: "die", attributes: map[string]string{"exitCode": "137"}},
+	}
+
+	for i, tc := range testCases {
+		event := events[i]
+		if event.Action != tc.action {
+			t.Errorf("event %d: expected action %q, got %q", i, tc.action, event.Action)
+		}
+		for key, value := range tc.attributes {
+			if event.Actor.Attributes[key] != value {
+				t.Errorf("event %d: expected attribute %s=%s, got %q", i, key, value, event.Actor.Attributes[key])
+			}
+		}
+		for _, key := range []string{"containerExitCode", "health_status"} {
+			if _, ok := event.Actor.Attributes[key]; ok {
+				t.Errorf("event %d: expected Podman attribute %s to be removed", i, key)
+			}
+		}
+		if event.Actor.Attributes["name"] != "worker" {
+			t.Errorf("event %d: expected name worker, got %q", i, event.Actor.Attributes["name"])
+		}
+	}
+}
+
+func TestPodman_ContainerLogs(t *testing.T) {
+	client := newPodmanServer(t)
+
+	logs, err := client.ContainerLogs(context.Background(), podmanWorkerID, ContainerLogsOptions{
+		Stdout:    true,
+		Stderr:    true,
+		Timestamp: true,
+		Since:     time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC).Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer logs.Close()
+
+	// The daemon speaks API 1.41, so whether the raw-stream response is
+	// framed is told by the TTY of the container.
+	if !logs.Multiplexed {
+		t.Fatal("expected the logs of a container without a TTY to be multiplexed")
+	}
+
+	expected := []struct {
+		stream StreamType
+		line   string
+	}{
+		{StreamStdout, "2024-04-15T10:00:00.123456789Z worker started"},
+		{StreamStderr, "2024-04-15T10:00:30.5Z ERROR failed to connect to queue"},
+		{StreamStdout, "2024-04-15T10:00:31.000000001Z retrying in 5s"},
+	}
+
+	scanner := NewLogScanner(logs, logs.Multiplexed)
+	for i, want := range expected {
+		if !scanner.Scan() {
+			t.Fatalf("expected line %d, scanner stopped: %v", i, scanner.Err())
+		}
+		if scanner.Stream() != want.stream || !bytes.Equal(scanner.Line(), []byte(want.line)) {
+			t.Errorf("line %d: expected %s %q, got %s %q", i, want.stream, want.line, scanner.Stream(), scanner.Line())
+		}
+	}
+	if scanner.Scan() {
+		t.Errorf("unexpected extra line %q", scanner.Line())
+	}
+}
+
+func TestDetectHost(t *testing.T) {
+	dir := t.TempDir()
+	podman := filepath.Join(dir, "podman.sock")
+
+	if host := detectHost([]string{filepath.Join(dir, "docker.sock"), podman}); host != DefaultHost {
+		t.Errorf("expected the default host without sockets, got %s", host)
+	}
+
+	if err := os.WriteFile(podman, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if host := detectHost([]string{filepath.Join(dir, "docker.sock"), podman}); host != "unix://"+podman {
+		t.Errorf("expected the Podman socket, got %s", host)
+	}
+}
+
+func TestPodmanSockets(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+	sockets := PodmanSockets()
+	expected := []string{"/run/podman/podman.sock", "/run/user/1000/podman/podman.sock"}
+	if len(sockets) != len(expected) || sockets[0] != expected[0] || sockets[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, sockets)
+	}
+}
@@ -0,0 +1,116 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+func (dc *DockerClient) ServiceList(ctx context.Context, opts ServiceListOptions) ([]Service, error) {
+	queryParams := url.Values{}
+
+	if opts.Filters.Len() > 0 {
+		filterJSON, err := opts.Filters.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		queryParams.Set("filters", filterJSON)
+	}
+
+	resp, err := dc.get(ctx, "/services", queryParams)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var services []Service
+	if err := json.NewDecoder(resp.Body).Decode(&services); err != nil {
+		return nil, fmt.Errorf("failed to decode service list: %w", err)
+	}
+
+	return services, nil
+}
+
+func (dc *DockerClient) TaskList(ctx context.Context, opts TaskListOptions) ([]Task, error) {
+	queryParams := url.Values{}
+
+	if opts.Filters.Len() > 0 {
+		filterJSON, err := opts.Filters.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		queryParams.Set("filters", filterJSON)
+	}
+
+	resp, err := dc.get(ctx, "/tasks", queryParams)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode task list: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ServiceLogs returns the logs of every task of the service. They are framed
+// like container logs. Daemons that do not tell a raw stream by its media
+// type are expected to send service logs framed.
+func (dc *DockerClient) ServiceLogs(ctx context.Context, serviceID string, opts ContainerLogsOptions) (*LogStream, error) {
+	queryParams, err := logsQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/services/%s/logs", serviceID)
+	resp, err := dc.get(ctx, endpoint, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return dc.logStream(ctx, resp, func(context.Context) (bool, error) {
+		return false, nil
+	})
+}
+
+// ParseLogDetails splits the details that the daemon puts in front of a log
+// line requested with ContainerLogsOptions.Details, such as
+// "com.docker.swarm.task.id=abc,com.docker.swarm.service.id=def message".
+// The details are comma-separated, URL-encoded key=value pairs.
+func ParseLogDetails(line []byte) (map[string]string, []byte, error) {
+	raw, rest, ok := bytes.Cut(line, []byte(" "))
+	if !ok {
+		raw, rest = line, nil
+	}
+
+	details := make(map[string]string)
+	if len(raw) == 0 {
+		return details, rest, nil
+	}
+
+	for _, pair := range bytes.Split(raw, []byte(",")) {
+		key, value, ok := bytes.Cut(pair, []byte("="))
+		if !ok {
+			return nil, line, fmt.Errorf("invalid log details %q", raw)
+		}
+
+		k, err := url.QueryUnescape(string(key))
+		if err != nil {
+			return nil, line, fmt.Errorf("invalid log details %q: %w", raw, err)
+		}
+		v, err := url.QueryUnescape(string(value))
+		if err != nil {
+			return nil, line, fmt.Errorf("invalid log details %q: %w", raw, err)
+		}
+		details[k] = v
+	}
+
+	return details, rest, nil
+}
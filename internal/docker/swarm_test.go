@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"testing"
+)
+
+func TestParseLogDetails(t *testing.T) {
+	testCases := []struct {
+		name            string
+		line            string
+		expectedDetails map[string]string
+		expectedRest    string
+		expectError     bool
+	}{
+		{
+			name: "swarm task",
+			line: "com.docker.swarm.node.id=node1,com.docker.swarm.service.id=service1,com.docker.swarm.task.id=task1 ERROR failed",
+			expectedDetails: map[string]string{
+				"com.docker.swarm.node.id":    "node1",
+				"com.docker.swarm.service.id": "service1",
+				"com.docker.swarm.task.id":    "task1",
+			},
+			expectedRest: "ERROR failed",
+		},
+		{
+			name:            "escaped values",
+			line:            "env=a%2Cb%3Dc message",
+			expectedDetails: map[string]string{"env": "a,b=c"},
+			expectedRest:    "message",
+		},
+		{
+			name:            "no details",
+			line:            " message",
+			expectedDetails: map[string]string{},
+			expectedRest:    "message",
+		},
+		{
+			name:        "not details",
+			line:        "plain message",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			details, rest, err := ParseLogDetails([]byte(tc.line))
+			if tc.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(rest) != tc.expectedRest {
+				t.Errorf("expected rest %q, got %q", tc.expectedRest, rest)
+			}
+			if len(details) != len(tc.expectedDetails) {
+				t.Fatalf("expected %v, got %v", tc.expectedDetails, details)
+			}
+			for key, value := range tc.expectedDetails {
+				if details[key] != value {
+					t.Errorf("expected %s=%s, got %q", key, value, details[key])
+				}
+			}
+		})
+	}
+}
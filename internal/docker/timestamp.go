@@ -5,11 +5,17 @@ import (
 	"time"
 )
 
-func parseTimestamp(s string) (int64, error) {
+func parseTimestamp(s string) (time.Time, error) {
 	t, err := time.Parse(time.RFC3339Nano, s)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse timestamp: %w", err)
+		return time.Time{}, fmt.Errorf("failed to parse timestamp: %w", err)
 	}
 
-	return t.Unix(), nil
+	return t, nil
+}
+
+// formatTimestamp formats t the way the API expects "since" and "until"
+// values: Unix seconds with an optional nanosecond fraction.
+func formatTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
 }
@@ -1,6 +1,183 @@
 package docker
 
 type Container struct {
-	ID    string   `json:"Id"`
-	Names []string `json:"Names"`
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	Labels  map[string]string `json:"Labels"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Created int64             `json:"Created"`
+}
+
+type EventActor struct {
+	ID         string            `json:"ID"`
+	Attributes map[string]string `json:"Attributes"`
+}
+
+type Event struct {
+	Type     string     `json:"Type"`
+	Action   string     `json:"Action"`
+	Actor    EventActor `json:"Actor"`
+	Scope    string     `json:"scope"`
+	Time     int64      `json:"time"`
+	TimeNano int64      `json:"timeNano"`
+}
+
+type HealthcheckResult struct {
+	Start    string `json:"Start"`
+	End      string `json:"End"`
+	ExitCode int    `json:"ExitCode"`
+	Output   string `json:"Output"`
+}
+
+type Health struct {
+	Status        string              `json:"Status"`
+	FailingStreak int                 `json:"FailingStreak"`
+	Log           []HealthcheckResult `json:"Log"`
+}
+
+type ContainerState struct {
+	Status     string  `json:"Status"`
+	Running    bool    `json:"Running"`
+	Paused     bool    `json:"Paused"`
+	Restarting bool    `json:"Restarting"`
+	OOMKilled  bool    `json:"OOMKilled"`
+	Dead       bool    `json:"Dead"`
+	Pid        int     `json:"Pid"`
+	ExitCode   int     `json:"ExitCode"`
+	Error      string  `json:"Error"`
+	StartedAt  string  `json:"StartedAt"`
+	FinishedAt string  `json:"FinishedAt"`
+	Health     *Health `json:"Health"`
+	// Healthcheck is where Podman before 4.3 reports Health.
+	Healthcheck *Health `json:"Healthcheck,omitempty"`
+}
+
+type ContainerConfig struct {
+	Hostname string            `json:"Hostname"`
+	Image    string            `json:"Image"`
+	Labels   map[string]string `json:"Labels"`
+	Tty      bool              `json:"Tty"`
+}
+
+type ContainerJSON struct {
+	ID           string           `json:"Id"`
+	Name         string           `json:"Name"`
+	Created      string           `json:"Created"`
+	Image        string           `json:"Image"`
+	RestartCount int              `json:"RestartCount"`
+	State        *ContainerState  `json:"State"`
+	Config       *ContainerConfig `json:"Config"`
+}
+
+type Info struct {
+	ID              string `json:"ID"`
+	Name            string `json:"Name"`
+	ServerVersion   string `json:"ServerVersion"`
+	OperatingSystem string `json:"OperatingSystem"`
+}
+
+type CPUUsage struct {
+	TotalUsage  uint64   `json:"total_usage"`
+	PercpuUsage []uint64 `json:"percpu_usage"`
+}
+
+type CPUStats struct {
+	CPUUsage    CPUUsage `json:"cpu_usage"`
+	SystemUsage uint64   `json:"system_cpu_usage"`
+	OnlineCPUs  uint32   `json:"online_cpus"`
+}
+
+type MemoryStats struct {
+	Usage uint64            `json:"usage"`
+	Limit uint64            `json:"limit"`
+	Stats map[string]uint64 `json:"stats"`
+}
+
+type PidsStats struct {
+	Current uint64 `json:"current"`
+	Limit   uint64 `json:"limit"`
+}
+
+type StatsJSON struct {
+	Read        string      `json:"read"`
+	PreRead     string      `json:"preread"`
+	CPUStats    CPUStats    `json:"cpu_stats"`
+	PreCPUStats CPUStats    `json:"precpu_stats"`
+	MemoryStats MemoryStats `json:"memory_stats"`
+	PidsStats   PidsStats   `json:"pids_stats"`
+}
+
+type ImageSummary struct {
+	ID         string   `json:"Id"`
+	RepoTags   []string `json:"RepoTags"`
+	Size       int64    `json:"Size"`
+	SharedSize int64    `json:"SharedSize"`
+	Containers int64    `json:"Containers"`
+}
+
+type VolumeUsageData struct {
+	Size     int64 `json:"Size"`
+	RefCount int64 `json:"RefCount"`
+}
+
+type Volume struct {
+	Name      string           `json:"Name"`
+	Driver    string           `json:"Driver"`
+	UsageData *VolumeUsageData `json:"UsageData"`
+}
+
+type BuildCache struct {
+	ID          string `json:"ID"`
+	Type        string `json:"Type"`
+	Description string `json:"Description"`
+	InUse       bool   `json:"InUse"`
+	Shared      bool   `json:"Shared"`
+	Size        int64  `json:"Size"`
+	LastUsedAt  string `json:"LastUsedAt"`
+	UsageCount  int64  `json:"UsageCount"`
+}
+
+type DiskUsage struct {
+	LayersSize int64          `json:"LayersSize"`
+	Images     []ImageSummary `json:"Images"`
+	Volumes    []Volume       `json:"Volumes"`
+	BuildCache []BuildCache   `json:"BuildCache"`
+}
+
+type ContainerSpec struct {
+	Image  string            `json:"Image"`
+	Labels map[string]string `json:"Labels"`
+}
+
+type TaskSpec struct {
+	ContainerSpec *ContainerSpec `json:"ContainerSpec"`
+}
+
+type ServiceSpec struct {
+	Name         string            `json:"Name"`
+	Labels       map[string]string `json:"Labels"`
+	TaskTemplate TaskSpec          `json:"TaskTemplate"`
+}
+
+type Service struct {
+	ID   string      `json:"ID"`
+	Spec ServiceSpec `json:"Spec"`
+}
+
+type TaskStatus struct {
+	State   string `json:"State"`
+	Message string `json:"Message"`
+	Err     string `json:"Err"`
+}
+
+type Task struct {
+	ID           string     `json:"ID"`
+	ServiceID    string     `json:"ServiceID"`
+	NodeID       string     `json:"NodeID"`
+	Slot         int        `json:"Slot"`
+	Status       TaskStatus `json:"Status"`
+	DesiredState string     `json:"DesiredState"`
 }
@@ -0,0 +1,83 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxAPIVersion is the newest Engine API version the client knows. Older
+// daemons are talked to with the version they report.
+const MaxAPIVersion = "1.45"
+
+type Version struct {
+	Version       string `json:"Version"`
+	APIVersion    string `json:"ApiVersion"`
+	MinAPIVersion string `json:"MinAPIVersion"`
+	Os            string `json:"Os"`
+	Arch          string `json:"Arch"`
+}
+
+// ServerVersion returns the version of the daemon.
+func (dc *DockerClient) ServerVersion(ctx context.Context) (Version, error) {
+	resp, err := dc.request(ctx, "/version", url.Values{})
+	if err != nil {
+		return Version{}, err
+	}
+	defer resp.Body.Close()
+
+	var version Version
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return Version{}, fmt.Errorf("failed to decode version: %w", err)
+	}
+
+	return version, nil
+}
+
+// apiVersion negotiates the API version on first use: the lower of
+// MaxAPIVersion and the version of the daemon. A failed negotiation is
+// retried with the next request.
+func (dc *DockerClient) apiVersion(ctx context.Context) (string, error) {
+	dc.versionMu.Lock()
+	defer dc.versionMu.Unlock()
+
+	if dc.version != "" {
+		return dc.version, nil
+	}
+
+	server, err := dc.ServerVersion(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to negotiate API version: %w", err)
+	}
+
+	dc.version = MaxAPIVersion
+	if server.APIVersion != "" && compareVersions(server.APIVersion, MaxAPIVersion) < 0 {
+		dc.version = server.APIVersion
+	}
+
+	return dc.version, nil
+}
+
+// compareVersions compares two dotted API versions such as "1.41".
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package logfilter
+
+// automaton is an Aho-Corasick automaton finding literals in a line in one
+// pass, ignoring ASCII case. Every literal names the patterns it is required
+// by.
+type automaton struct {
+	// classes maps a byte to its column in delta. Bytes found in no literal
+	// share column 0, and upper case ASCII letters share the column of their
+	// lower case.
+	classes [256]uint16
+	stride  int
+	// delta is the transition table of the complete automaton, stride
+	// columns per state. State 0 is the root.
+	delta []int32
+	// out lists the patterns whose literals end in a state.
+	out [][]int
+}
+
+func newAutomaton(literals []string, owners [][]int) *automaton {
+	a := &automaton{}
+
+	n := 1
+	for _, literal := range literals {
+		for i := 0; i < len(literal); i++ {
+			b := lowerASCII(literal[i])
+			if a.classes[b] == 0 {
+				a.classes[b] = uint16(n)
+				n++
+			}
+		}
+	}
+	for b := 'A'; b <= 'Z'; b++ {
+		a.classes[b] = a.classes[b+'a'-'A']
+	}
+	a.stride = n
+
+	a.addState()
+	for i, literal := range literals {
+		state := 0
+		for j := 0; j < len(literal); j++ {
+			cell := state*a.stride + int(a.classes[literal[j]])
+			if a.delta[cell] < 0 {
+				next := a.addState()
+				a.delta[cell] = int32(next)
+			}
+			state = int(a.delta[cell])
+		}
+		a.out[state] = append(a.out[state], owners[i]...)
+	}
+
+	a.link()
+	return a
+}
+
+func (a *automaton) addState() int {
+	for i := 0; i < a.stride; i++ {
+		a.delta = append(a.delta, -1)
+	}
+	a.out = append(a.out, nil)
+	return len(a.out) - 1
+}
+
+// link turns the trie into the complete automaton, replacing missing
+// transitions with those of the longest proper suffix in the trie.
+func (a *automaton) link() {
+	fail := make([]int32, len(a.out))
+	queue := make([]int32, 0, len(a.out))
+
+	for c := 0; c < a.stride; c++ {
+		if next := a.delta[c]; next < 0 {
+			a.delta[c] = 0
+		} else {
+			queue = append(queue, next)
+		}
+	}
+
+	// States are visited by depth, so the outputs of the suffix state are
+	// complete when they are merged.
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		a.out[state] = append(a.out[state], a.out[fail[state]]...)
+
+		row := int(state) * a.stride
+		failRow := int(fail[state]) * a.stride
+		for c := 0; c < a.stride; c++ {
+			if next := a.delta[row+c]; next < 0 {
+				a.delta[row+c] = a.delta[failRow+c]
+			} else {
+				fail[next] = a.delta[failRow+c]
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// mark sets the bits of the patterns with a literal in content.
+func (a *automaton) mark(content []byte, bits []uint64) {
+	state := int32(0)
+	for _, b := range content {
+		state = a.delta[int(state)*a.stride+int(a.classes[b])]
+		for _, i := range a.out[state] {
+			bits[i/64] |= 1 << (i % 64)
+		}
+	}
+}
+
+func lowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+package logfilter
+
+import "testing"
+
+func TestAutomaton_allBytes(t *testing.T) {
+	// One literal per byte value, and one of all of them, so that every byte
+	// but the upper case letters needs a column of its own.
+	var literals []string
+	var owners [][]int
+	all := make([]byte, 0, 256)
+	for b := 0; b < 256; b++ {
+		literals = append(literals, string([]byte{byte(b), byte(b)}))
+		owners = append(owners, []int{b})
+		all = append(all, byte(b))
+	}
+	literals = append(literals, string(all))
+	owners = append(owners, []int{256})
+
+	a := newAutomaton(literals, owners)
+
+	for b := 0; b < 256; b++ {
+		bits := make([]uint64, 5)
+		a.mark([]byte{byte(b), byte(b)}, bits)
+
+		for i := 0; i <= 256; i++ {
+			want := i == b || i == int(lowerASCII(byte(b))) || i == int(upperASCII(byte(b)))
+			if got := bits[i/64]&(1<<(i%64)) != 0; got != want {
+				t.Fatalf("byte %#02x twice: literal %d found %v, want %v", b, i, got, want)
+			}
+		}
+	}
+
+	bits := make([]uint64, 5)
+	a.mark(all, bits)
+	if bits[4]&1 == 0 {
+		t.Error("expected the literal of all bytes to be found")
+	}
+}
+
+func upperASCII(b byte) byte {
+	if 'a' <= b && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
@@ -0,0 +1,207 @@
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format is the format of the log line content.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatLogfmt Format = "logfmt"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(s))); format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON, FormatLogfmt:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format '%s'", s)
+	}
+}
+
+// ParseFields decodes a structured log line. It fails for lines that are not
+// in the format, and for the text format.
+func ParseFields(format Format, content []byte) (Fields, error) {
+	switch format {
+	case FormatJSON:
+		return ParseJSON(content)
+	case FormatLogfmt:
+		return ParseLogfmt(content)
+	default:
+		return nil, fmt.Errorf("log format '%s' has no fields", format)
+	}
+}
+
+// Fields are the fields of a structured log line. Nested keys are joined
+// with dots, e.g. http.status.
+type Fields map[string]string
+
+// Field is a field extracted into an alert.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Select returns the non-empty fields among names, in the order of names.
+func (f Fields) Select(names []string) []Field {
+	var res []Field
+	for _, name := range names {
+		if value := f[name]; value != "" {
+			res = append(res, Field{Key: name, Value: value})
+		}
+	}
+	return res
+}
+
+type operator string
+
+const (
+	opExists operator = "exists"
+	opEq     operator = "=="
+	opNe     operator = "!="
+	opGt     operator = ">"
+	opGe     operator = ">="
+	opLt     operator = "<"
+	opLe     operator = "<="
+	opIn     operator = "in"
+)
+
+// FieldRule is a condition on one field of a structured log line.
+type FieldRule struct {
+	Key    string
+	op     operator
+	values []string
+}
+
+var (
+	fieldKeyRe     = regexp.MustCompile(`^[\w.@-]+$`)
+	fieldCompareRe = regexp.MustCompile(`^([\w.@-]+)\s*(==|!=|>=|<=|>|<)\s*(.+)$`)
+	fieldInRe      = regexp.MustCompile(`^([\w.@-]+)\s+in\s*\((.*)\)$`)
+)
+
+// ParseFieldRule parses a rule such as "level in (error, fatal)",
+// "status >= 500", "env == prod" or "error". A bare key matches lines that
+// have the field. Equality ignores case unless both values are numbers;
+// ordering only applies to numbers.
+func ParseFieldRule(expr string) (*FieldRule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if m := fieldInRe.FindStringSubmatch(expr); m != nil {
+		var values []string
+		for _, value := range strings.Split(m[2], ",") {
+			if value = unquote(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid field rule '%s': empty value list", expr)
+		}
+		return &FieldRule{Key: m[1], op: opIn, values: values}, nil
+	}
+
+	if m := fieldCompareRe.FindStringSubmatch(expr); m != nil {
+		rule := &FieldRule{Key: m[1], op: operator(m[2]), values: []string{unquote(m[3])}}
+		switch rule.op {
+		case opGt, opGe, opLt, opLe:
+			if _, err := strconv.ParseFloat(rule.values[0], 64); err != nil {
+				return nil, fmt.Errorf("invalid field rule '%s': %s needs a number", expr, rule.op)
+			}
+		}
+		return rule, nil
+	}
+
+	if fieldKeyRe.MatchString(expr) {
+		return &FieldRule{Key: expr, op: opExists}, nil
+	}
+
+	return nil, fmt.Errorf("invalid field rule '%s'", expr)
+}
+
+func ParseFieldRules(exprs []string) ([]*FieldRule, error) {
+	rules := make([]*FieldRule, 0, len(exprs))
+	for _, expr := range exprs {
+		rule, err := ParseFieldRule(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *FieldRule) Match(fields Fields) bool {
+	value, ok := fields[r.Key]
+	if !ok {
+		return false
+	}
+
+	switch r.op {
+	case opExists:
+		return true
+	case opEq:
+		return equalValues(value, r.values[0])
+	case opNe:
+		return !equalValues(value, r.values[0])
+	case opIn:
+		for _, want := range r.values {
+			if equalValues(value, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	got, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	want, _ := strconv.ParseFloat(r.values[0], 64)
+
+	switch r.op {
+	case opGt:
+		return got > want
+	case opGe:
+		return got >= want
+	case opLt:
+		return got < want
+	case opLe:
+		return got <= want
+	}
+	return false
+}
+
+func (r *FieldRule) String() string {
+	switch r.op {
+	case opExists:
+		return r.Key
+	case opIn:
+		return fmt.Sprintf("%s in (%s)", r.Key, strings.Join(r.values, ", "))
+	default:
+		return fmt.Sprintf("%s %s %s", r.Key, r.op, r.values[0])
+	}
+}
+
+func equalValues(a, b string) bool {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return x == y
+	}
+	return strings.EqualFold(a, b)
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,123 @@
+package logfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestParseFieldRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "in", expr: "level in (error, fatal)", want: "level in (error, fatal)"},
+		{name: "in with quotes", expr: `level in ("error",'fatal')`, want: "level in (error, fatal)"},
+		{name: "greater or equal", expr: "status>=500", want: "status >= 500"},
+		{name: "equal", expr: `env == "prod"`, want: "env == prod"},
+		{name: "not equal", expr: "http.method != GET", want: "http.method != GET"},
+		{name: "presence", expr: " error ", want: "error"},
+		{name: "ordering needs a number", expr: "level > error", wantErr: true},
+		{name: "empty value list", expr: "level in ()", wantErr: true},
+		{name: "garbage", expr: "level is error", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := logfilter.ParseFieldRule(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFieldRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("ParseFieldRule() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldRule_Match(t *testing.T) {
+	fields := logfilter.Fields{
+		"level":       "ERROR",
+		"status":      "503",
+		"http.method": "POST",
+		"latency":     "0.25",
+		"error":       "",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "level in (error, fatal)", want: true},
+		{expr: "level in (warn, info)", want: false},
+		{expr: "level == error", want: true},
+		{expr: "level != error", want: false},
+		{expr: "status >= 500", want: true},
+		{expr: "status < 500", want: false},
+		{expr: "status == 503.0", want: true},
+		{expr: "latency > 0.2", want: true},
+		{expr: "level > 1", want: false},
+		{expr: "http.method == post", want: true},
+		{expr: "error", want: true},
+		{expr: "trace_id", want: false},
+		{expr: "trace_id != abc", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			rule, err := logfilter.ParseFieldRule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFieldRule() error = %v", err)
+			}
+			if got := rule.Match(fields); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields_Select(t *testing.T) {
+	fields := logfilter.Fields{"msg": "payment failed", "trace_id": "abc123", "error": "", "level": "error"}
+
+	got := fields.Select([]string{"msg", "message", "trace_id", "error"})
+	want := []logfilter.Field{
+		{Key: "msg", Value: "payment failed"},
+		{Key: "trace_id", Value: "abc123"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Select() = %v, want %v", got, want)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    logfilter.Format
+		wantErr bool
+	}{
+		{input: "", want: logfilter.FormatText},
+		{input: "text", want: logfilter.FormatText},
+		{input: "JSON", want: logfilter.FormatJSON},
+		{input: "logfmt", want: logfilter.FormatLogfmt},
+		{input: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := logfilter.ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package logfilter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var errNotJSONObject = errors.New("not a JSON object")
+
+// ParseJSON decodes a log line holding a JSON object. Nested objects are
+// flattened into dotted keys, and arrays are kept as JSON.
+func ParseJSON(content []byte) (Fields, error) {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || content[0] != '{' {
+		return nil, errNotJSONObject
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("malformed JSON log line: %w", err)
+	}
+
+	fields := make(Fields, len(object))
+	flatten(fields, "", object)
+	return fields, nil
+}
+
+func flatten(fields Fields, prefix string, object map[string]any) {
+	for key, value := range object {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(fields, key, v)
+		case string:
+			fields[key] = v
+		case json.Number:
+			fields[key] = v.String()
+		case nil:
+			fields[key] = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			fields[key] = string(encoded)
+		}
+	}
+}
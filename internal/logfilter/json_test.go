@@ -0,0 +1,55 @@
+package logfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    logfilter.Fields
+		wantErr bool
+	}{
+		{
+			name:  "flat object",
+			input: `{"level":"error","msg":"payment failed","status":503,"retry":false}`,
+			want:  logfilter.Fields{"level": "error", "msg": "payment failed", "status": "503", "retry": "false"},
+		},
+		{
+			name:  "nested object and array",
+			input: ` {"http":{"status":500,"path":"/pay"},"tags":["a","b"],"error":null}`,
+			want:  logfilter.Fields{"http.status": "500", "http.path": "/pay", "tags": `["a","b"]`, "error": ""},
+		},
+		{
+			name:  "large number keeps its digits",
+			input: `{"id":12345678901234567890}`,
+			want:  logfilter.Fields{"id": "12345678901234567890"},
+		},
+		{
+			name:    "plain text",
+			input:   "ERROR payment failed",
+			wantErr: true,
+		},
+		{
+			name:    "truncated object",
+			input:   `{"level":"error"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logfilter.ParseJSON([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
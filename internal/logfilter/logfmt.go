@@ -0,0 +1,87 @@
+package logfilter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var errNotLogfmt = errors.New("not a logfmt line")
+
+// ParseLogfmt decodes a logfmt line such as
+// level=error msg="payment failed" err=timeout. A key without a value, such
+// as debug in level=error debug, is present with an empty value. The line
+// must start with a key=value pair, so that plain text lines are not
+// mistaken for logfmt.
+func ParseLogfmt(content []byte) (Fields, error) {
+	fields := make(Fields)
+	rest := bytes.TrimSpace(content)
+
+	for len(rest) > 0 {
+		token := rest
+		if i := bytes.IndexAny(rest, " \t"); i >= 0 {
+			token = rest[:i]
+		}
+		eq := bytes.IndexByte(token, '=')
+		if eq < 0 && len(fields) > 0 && bytes.IndexByte(token, '"') < 0 {
+			fields[string(token)] = ""
+			rest = bytes.TrimLeft(rest[len(token):], " \t")
+			continue
+		}
+		if eq <= 0 || bytes.IndexByte(token[:eq], '"') >= 0 {
+			return nil, errNotLogfmt
+		}
+		key := string(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if len(rest) > 0 && rest[0] == '"' {
+			end := closingQuote(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("malformed logfmt line: unterminated value of %s", key)
+			}
+
+			unquoted, err := strconv.Unquote(string(rest[:end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("malformed logfmt line: value of %s: %w", key, err)
+			}
+			value = unquoted
+			rest = rest[end+1:]
+
+			if len(rest) > 0 && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, errNotLogfmt
+			}
+		} else {
+			end := bytes.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value = string(rest[:end])
+			rest = rest[end:]
+		}
+
+		fields[key] = value
+		rest = bytes.TrimLeft(rest, " \t")
+	}
+
+	if len(fields) == 0 {
+		return nil, errNotLogfmt
+	}
+
+	return fields, nil
+}
+
+// closingQuote returns the index of the quote that ends the quoted value at
+// the start of s, or -1.
+func closingQuote(s []byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,75 @@
+package logfilter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    logfilter.Fields
+		wantErr bool
+	}{
+		{
+			name:  "bare and quoted values",
+			input: `level=error msg="payment failed" err="dial tcp: i/o timeout" status=503`,
+			want:  logfilter.Fields{"level": "error", "msg": "payment failed", "err": "dial tcp: i/o timeout", "status": "503"},
+		},
+		{
+			name:  "escaped quotes and empty values",
+			input: `ts=2024-01-01T10:00:00Z msg="said \"no\"\n" caller= user=""`,
+			want:  logfilter.Fields{"ts": "2024-01-01T10:00:00Z", "msg": "said \"no\"\n", "caller": "", "user": ""},
+		},
+		{
+			name:  "value with equal sign",
+			input: "query=a=b  level=warn",
+			want:  logfilter.Fields{"query": "a=b", "level": "warn"},
+		},
+		{
+			name:  "bare keys",
+			input: "level=error debug msg=\"payment failed\" retry",
+			want:  logfilter.Fields{"level": "error", "debug": "", "msg": "payment failed", "retry": ""},
+		},
+		{
+			name:    "bare key first",
+			input:   "debug level=error",
+			wantErr: true,
+		},
+		{
+			name:    "plain text",
+			input:   "ERROR payment failed",
+			wantErr: true,
+		},
+		{
+			name:    "text with a key=value pair",
+			input:   "retrying request id=5",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			input:   `level=error msg="payment failed`,
+			wantErr: true,
+		},
+		{
+			name:    "empty line",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := logfilter.ParseLogfmt([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLogfmt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLogfmt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package logfilter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+const DefaultMultilineMaxLines = 50
+
+// MultilineOptions decide which log lines belong to the same event.
+type MultilineOptions struct {
+	// Start matches the first line of an event. Lines that do not match it
+	// continue the current event.
+	Start *regexp.Regexp
+	// Continuation matches lines that continue the current event.
+	Continuation *regexp.Regexp
+	// MaxLines limits the lines kept per event. Further lines are only
+	// counted.
+	MaxLines int
+}
+
+var multilinePresets = map[string]MultilineOptions{
+	// Stack frames, "Caused by:" chains and "... 12 more" lines.
+	"java": {
+		Continuation: regexp.MustCompile(`^(\s+at\s|\s+\.\.\.\s\d+\s(more|common frames omitted)|\s*Caused by:|\s*Suppressed:)`),
+	},
+	// Tracebacks follow the logged message, and end with the exception line.
+	"python": {
+		Continuation: regexp.MustCompile(`^(\s|Traceback \(most recent call last\):|During handling of the above exception|The above exception was the direct cause|[A-Za-z_][\w.]*(Error|Exception|Exit|Interrupt|Warning)(:|$))`),
+	},
+	// Goroutine dumps of a panic or a fatal error.
+	"go": {
+		Continuation: regexp.MustCompile(`^(\s|$|goroutine \d+ \[|created by |[\w./*()-]+\(.*\)$|\[signal |exit status \d+)`),
+	},
+	// Stack frames of an uncaught error.
+	"node": {
+		Continuation: regexp.MustCompile(`^(\s+at\s|\s+\.\.\.\s\d+ more|\s*\^+\s*$)`),
+	},
+}
+
+// MultilinePresets returns the names of the built-in presets.
+func MultilinePresets() []string {
+	return []string{"java", "python", "go", "node"}
+}
+
+// NewMultilineOptions builds options from a preset, custom start and
+// continuation patterns, or both. Custom patterns replace those of the
+// preset. Without any, nil is returned and lines are not aggregated.
+func NewMultilineOptions(preset, start, continuation string, maxLines int) (*MultilineOptions, error) {
+	if preset == "" && start == "" && continuation == "" {
+		return nil, nil
+	}
+
+	var opts MultilineOptions
+	if preset != "" {
+		p, ok := multilinePresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown multiline preset '%s', expected one of %v", preset, MultilinePresets())
+		}
+		opts = p
+	}
+
+	if start != "" {
+		re, err := regexp.Compile(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multiline start pattern '%s': %w", start, err)
+		}
+		opts.Start = re
+	}
+
+	if continuation != "" {
+		re, err := regexp.Compile(continuation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid multiline continuation pattern '%s': %w", continuation, err)
+		}
+		opts.Continuation = re
+	}
+
+	opts.MaxLines = maxLines
+	if opts.MaxLines <= 0 {
+		opts.MaxLines = DefaultMultilineMaxLines
+	}
+
+	return &opts, nil
+}
+
+func (o *MultilineOptions) continues(content []byte) bool {
+	if o.Continuation != nil && o.Continuation.Match(content) {
+		return true
+	}
+	return o.Start != nil && !o.Start.Match(content)
+}
+
+// Aggregator groups continuation lines with the line that started them into
+// one event, so that a stack trace is matched and reported as a whole.
+type Aggregator struct {
+	opts    *MultilineOptions
+	pending *MatchedLine
+	lines   int
+}
+
+func NewAggregator(opts *MultilineOptions) *Aggregator {
+	return &Aggregator{opts: opts}
+}
+
+// Add feeds the next line. When the line starts a new event, the previous
+// event is complete and returned. The line is copied. Lines of another
+// stream than the event's never continue it, as the streams of a container
+// interleave.
+func (a *Aggregator) Add(stream Stream, timestamp, content []byte) *MatchedLine {
+	if a.pending != nil && a.pending.Stream == stream && a.opts.continues(content) {
+		if a.lines < a.opts.MaxLines {
+			a.pending.Content = append(append(a.pending.Content, '\n'), content...)
+			a.lines++
+		} else {
+			a.pending.Omitted++
+		}
+		return nil
+	}
+
+	event := a.Flush()
+	a.pending = &MatchedLine{
+		Timestamp: bytes.Clone(timestamp),
+		Content:   bytes.Clone(content),
+		Stream:    stream,
+	}
+	a.lines = 1
+	return event
+}
+
+// Flush returns the pending event, if any, as complete.
+func (a *Aggregator) Flush() *MatchedLine {
+	event := a.pending
+	a.pending = nil
+	a.lines = 0
+	return event
+}
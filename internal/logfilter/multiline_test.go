@@ -0,0 +1,219 @@
+package logfilter_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestAggregator(t *testing.T) {
+	tests := []struct {
+		name         string
+		preset       string
+		start        string
+		continuation string
+		maxLines     int
+		lines        []string
+		// stderr holds the indexes of the lines written to stderr, the others
+		// go to stdout.
+		stderr      []int
+		want        []string
+		wantOmitted []int
+	}{
+		{
+			name:   "java exception",
+			preset: "java",
+			lines: []string{
+				"INFO starting",
+				`Exception in thread "main" java.lang.IllegalStateException: boom`,
+				"\tat com.example.App.run(App.java:10)",
+				"\tat com.example.App.main(App.java:5)",
+				"Caused by: java.io.IOException: disk full",
+				"\t... 2 more",
+				"INFO stopped",
+			},
+			want: []string{
+				"INFO starting",
+				"Exception in thread \"main\" java.lang.IllegalStateException: boom\n\tat com.example.App.run(App.java:10)\n\tat com.example.App.main(App.java:5)\nCaused by: java.io.IOException: disk full\n\t... 2 more",
+				"INFO stopped",
+			},
+		},
+		{
+			name:   "python traceback",
+			preset: "python",
+			lines: []string{
+				"ERROR request failed",
+				"Traceback (most recent call last):",
+				`  File "app.py", line 3, in <module>`,
+				"    main()",
+				"ValueError: invalid literal",
+				"INFO next request",
+			},
+			want: []string{
+				"ERROR request failed\nTraceback (most recent call last):\n  File \"app.py\", line 3, in <module>\n    main()\nValueError: invalid literal",
+				"INFO next request",
+			},
+		},
+		{
+			name:   "go panic",
+			preset: "go",
+			lines: []string{
+				"panic: runtime error: index out of range [3] with length 3",
+				"",
+				"goroutine 1 [running]:",
+				"main.main()",
+				"\t/app/main.go:8 +0x1d",
+				"exit status 2",
+			},
+			want: []string{
+				"panic: runtime error: index out of range [3] with length 3\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:8 +0x1d\nexit status 2",
+			},
+		},
+		{
+			name:   "node error",
+			preset: "node",
+			lines: []string{
+				"Error: connect ECONNREFUSED 127.0.0.1:5432",
+				"    at TCPConnectWrap.afterConnect (node:net:1555:16)",
+				"Server listening",
+			},
+			want: []string{
+				"Error: connect ECONNREFUSED 127.0.0.1:5432\n    at TCPConnectWrap.afterConnect (node:net:1555:16)",
+				"Server listening",
+			},
+		},
+		{
+			name:  "custom start pattern",
+			start: `^\d{2}:\d{2} `,
+			lines: []string{
+				"10:00 ok",
+				"10:01 job failed",
+				"detail one",
+				"detail two",
+				"10:02 ok",
+			},
+			want: []string{"10:00 ok", "10:01 job failed\ndetail one\ndetail two", "10:02 ok"},
+		},
+		{
+			name:     "lines beyond the limit are counted",
+			preset:   "java",
+			maxLines: 2,
+			lines: []string{
+				"java.lang.RuntimeException: boom",
+				"\tat a.b(C.java:1)",
+				"\tat a.c(C.java:2)",
+				"\tat a.d(C.java:3)",
+			},
+			want:        []string{"java.lang.RuntimeException: boom\n\tat a.b(C.java:1)"},
+			wantOmitted: []int{2},
+		},
+		{
+			name:   "continuation lines join the event before them",
+			preset: "java",
+			lines: []string{
+				"INFO retrying",
+				"\tat ERROR.handler(Retry.java:1)",
+				"java.lang.Exception: ERROR",
+			},
+			want: []string{"INFO retrying\n\tat ERROR.handler(Retry.java:1)", "java.lang.Exception: ERROR"},
+		},
+		{
+			name:   "lines of another stream do not continue an event",
+			preset: "java",
+			lines: []string{
+				"java.lang.RuntimeException: boom",
+				"\tat a.b(C.java:1)",
+				"\tat progress 50%",
+			},
+			stderr: []int{0, 1},
+			want:   []string{"java.lang.RuntimeException: boom\n\tat a.b(C.java:1)", "\tat progress 50%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := logfilter.NewMultilineOptions(tt.preset, tt.start, tt.continuation, tt.maxLines)
+			if err != nil {
+				t.Fatalf("NewMultilineOptions() error = %v", err)
+			}
+
+			aggregator := logfilter.NewAggregator(opts)
+			var got []*logfilter.MatchedLine
+			for i, line := range tt.lines {
+				stream := logfilter.StreamStdout
+				if slices.Contains(tt.stderr, i) {
+					stream = logfilter.StreamStderr
+				}
+				timestamp := fmt.Sprintf("2023-11-15T10:00:%02dZ", i)
+				if event := aggregator.Add(stream, []byte(timestamp), []byte(line)); event != nil {
+					got = append(got, event)
+				}
+			}
+			if event := aggregator.Flush(); event != nil {
+				got = append(got, event)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Aggregator got %d events, want %d", len(got), len(tt.want))
+			}
+
+			first := 0
+			for i := range got {
+				if string(got[i].Content) != tt.want[i] {
+					t.Errorf("Aggregator content[%d] = %q, want %q", i, got[i].Content, tt.want[i])
+				}
+				if want := fmt.Sprintf("2023-11-15T10:00:%02dZ", first); string(got[i].Timestamp) != want {
+					t.Errorf("Aggregator timestamp[%d] = %s, want %s", i, got[i].Timestamp, want)
+				}
+				first += strings.Count(tt.want[i], "\n") + 1
+
+				wantOmitted := 0
+				if i < len(tt.wantOmitted) {
+					wantOmitted = tt.wantOmitted[i]
+				}
+				if got[i].Omitted != wantOmitted {
+					t.Errorf("Aggregator omitted[%d] = %d, want %d", i, got[i].Omitted, wantOmitted)
+				}
+				first += wantOmitted
+			}
+		})
+	}
+}
+
+func TestNewMultilineOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		preset       string
+		start        string
+		continuation string
+		wantNil      bool
+		wantErr      bool
+	}{
+		{name: "disabled", wantNil: true},
+		{name: "preset", preset: "python"},
+		{name: "unknown preset", preset: "cobol", wantErr: true},
+		{name: "invalid start pattern", start: "(", wantErr: true},
+		{name: "invalid continuation pattern", continuation: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := logfilter.NewMultilineOptions(tt.preset, tt.start, tt.continuation, 0)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewMultilineOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (opts == nil) != tt.wantNil {
+				t.Fatalf("NewMultilineOptions() = %v, wantNil %v", opts, tt.wantNil)
+			}
+			if opts != nil && opts.MaxLines != logfilter.DefaultMultilineMaxLines {
+				t.Errorf("NewMultilineOptions() MaxLines = %d, want the default", opts.MaxLines)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package logfilter
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"unicode"
+)
+
+// PatternSet finds the first of many patterns that matches a line. Most
+// patterns require a literal, such as "error" in `(?i)error: \w+`; all of
+// them are searched for in one pass, and only the patterns whose literal was
+// found, or that have none, are run.
+type PatternSet struct {
+	patterns []*regexp.Regexp
+	// always marks the patterns without a literal.
+	always    []uint64
+	automaton *automaton
+}
+
+func NewPatternSet(patterns []*regexp.Regexp) *PatternSet {
+	s := &PatternSet{
+		patterns: patterns,
+		always:   make([]uint64, (len(patterns)+63)/64),
+	}
+
+	var literals []string
+	var owners [][]int
+	index := make(map[string]int)
+
+	for i, pattern := range patterns {
+		required, ok := patternLiterals(pattern)
+		if !ok {
+			s.always[i/64] |= 1 << (i % 64)
+			continue
+		}
+
+		for _, literal := range required {
+			j, ok := index[literal]
+			if !ok {
+				j = len(literals)
+				index[literal] = j
+				literals = append(literals, literal)
+				owners = append(owners, nil)
+			}
+			owners[j] = append(owners[j], i)
+		}
+	}
+
+	if len(literals) > 0 {
+		s.automaton = newAutomaton(literals, owners)
+	}
+	return s
+}
+
+// FirstMatch returns the index of the first pattern that matches content, or
+// -1 when none does.
+func (s *PatternSet) FirstMatch(content []byte) int {
+	var small [4]uint64
+	candidates := s.candidates(content, small[:0])
+	return s.firstMatch(candidates, 0, len(s.patterns), content)
+}
+
+// candidates returns the patterns that may match content as a bit set, using
+// buf when it is large enough.
+func (s *PatternSet) candidates(content []byte, buf []uint64) []uint64 {
+	if cap(buf) < len(s.always) {
+		buf = make([]uint64, len(s.always))
+	}
+	buf = buf[:len(s.always)]
+	copy(buf, s.always)
+
+	if s.automaton != nil {
+		s.automaton.mark(content, buf)
+	}
+	return buf
+}
+
+// firstMatch runs the candidate patterns from index from up to to in order.
+func (s *PatternSet) firstMatch(candidates []uint64, from, to int, content []byte) int {
+	for i := from; i < to; i++ {
+		if candidates[i/64]&(1<<(i%64)) != 0 && s.patterns[i].Match(content) {
+			return i
+		}
+	}
+	return -1
+}
+
+// patternLiterals returns literals, one of which is part of every match of
+// the pattern.
+func patternLiterals(pattern *regexp.Regexp) ([]string, bool) {
+	re, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	return requiredLiterals(re)
+}
+
+func requiredLiterals(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return literalFactor(re.Rune, re.Flags&syntax.FoldCase != 0)
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Any of the parts will do, the one with the longest literals
+		// finds the fewest candidates.
+		var best []string
+		for _, sub := range re.Sub {
+			if literals, ok := requiredLiterals(sub); ok && shortest(literals) > shortest(best) {
+				best = literals
+			}
+		}
+		return best, best != nil
+	case syntax.OpAlternate:
+		var all []string
+		for _, sub := range re.Sub {
+			literals, ok := requiredLiterals(sub)
+			if !ok {
+				return nil, false
+			}
+			all = append(all, literals...)
+		}
+		return all, true
+	}
+	return nil, false
+}
+
+// literalFactor returns the longest part of a literal that is found by an
+// ASCII case-insensitive search. Case-insensitive literals are cut at runes
+// that fold to other than ASCII letters, such as 'k' to the Kelvin sign.
+func literalFactor(runes []rune, foldCase bool) ([]string, bool) {
+	if !foldCase {
+		if len(runes) == 0 {
+			return nil, false
+		}
+		return []string{string(runes)}, true
+	}
+
+	var best, current []rune
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && foldsToASCII(runes[i]) {
+			current = append(current, runes[i])
+			continue
+		}
+		if len(current) > len(best) {
+			best = current
+		}
+		current = nil
+	}
+
+	if len(best) == 0 {
+		return nil, false
+	}
+	return []string{string(best)}, true
+}
+
+func foldsToASCII(r rune) bool {
+	if r >= 0x80 {
+		return false
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func shortest(literals []string) int {
+	if len(literals) == 0 {
+		return 0
+	}
+
+	n := len(literals[0])
+	for _, literal := range literals[1:] {
+		n = min(n, len(literal))
+	}
+	return n
+}
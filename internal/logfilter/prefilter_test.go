@@ -0,0 +1,234 @@
+package logfilter_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func compilePatterns(t testing.TB, patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			t.Fatalf("invalid pattern %q: %v", pattern, err)
+		}
+		res[i] = re
+	}
+	return res
+}
+
+func TestPatternSet_FirstMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		content  string
+		want     int
+	}{
+		{
+			name:     "no match",
+			patterns: []string{"(?i)error", "panic:"},
+			content:  "request handled in 12ms",
+			want:     -1,
+		},
+		{
+			name:     "first of several matches",
+			patterns: []string{"(?i)timeout", "(?i)error", "ERROR"},
+			content:  "ERROR: database error",
+			want:     1,
+		},
+		{
+			name:     "case-insensitive literal",
+			patterns: []string{"(?i)connection (refused|reset)"},
+			content:  "Connection RESET by peer",
+			want:     0,
+		},
+		{
+			name:     "case-sensitive literal",
+			patterns: []string{"ERROR", "(?i)error"},
+			content:  "error: boom",
+			want:     1,
+		},
+		{
+			name:     "literal found only in another case",
+			patterns: []string{"ORA-\\d+"},
+			content:  "ora-00060",
+			want:     -1,
+		},
+		{
+			name:     "alternation",
+			patterns: []string{"(?i)(fatal|panic)"},
+			content:  "PANIC in handler",
+			want:     0,
+		},
+		{
+			name:     "pattern without a literal",
+			patterns: []string{"(?i)error", `\b5\d\d\b`},
+			content:  `"GET / HTTP/1.1" 503`,
+			want:     1,
+		},
+		{
+			name:     "optional literal",
+			patterns: []string{`(?i)(out of )?memory`},
+			content:  "memory exhausted",
+			want:     0,
+		},
+		{
+			name:     "case folding beyond ASCII",
+			patterns: []string{"(?i)disk full"},
+			content:  "DIS\u212A FULL",
+			want:     0,
+		},
+		{
+			name:     "more than 64 patterns",
+			patterns: repeat("(?i)never", 69),
+			content:  "nothing",
+			want:     -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := logfilter.NewPatternSet(compilePatterns(t, tt.patterns))
+			if got := set.FirstMatch([]byte(tt.content)); got != tt.want {
+				t.Errorf("expected pattern %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func repeat(s string, n int) []string {
+	res := make([]string, n)
+	for i := range res {
+		res[i] = s
+	}
+	return res
+}
+
+// TestPatternSet_FirstMatch_regexps compares the set with running every
+// pattern in order.
+func TestPatternSet_FirstMatch_regexps(t *testing.T) {
+	patterns := compilePatterns(t, benchmarkPatterns)
+	set := logfilter.NewPatternSet(patterns)
+
+	for _, line := range bytes.Split(benchmarkLogs(500), []byte{'\n'}) {
+		want := -1
+		for i, pattern := range patterns {
+			if pattern.Match(line) {
+				want = i
+				break
+			}
+		}
+
+		if got := set.FirstMatch(line); got != want {
+			t.Errorf("%q: expected pattern %d, got %d", line, want, got)
+		}
+	}
+}
+
+var benchmarkPatterns = []string{
+	"(?i)error", "(?i)fatal", "(?i)panic:", "(?i)exception", "(?i)traceback",
+	"(?i)segmentation fault", "(?i)out of memory", "(?i)oomkilled", "(?i)killed process",
+	"(?i)connection (refused|reset|timed out)", "(?i)broken pipe", "(?i)no route to host",
+	"(?i)deadlock", "(?i)lock wait timeout", `ORA-\d{5}`, `(?i)SQLSTATE\[\w+\]`,
+	"(?i)too many connections", "(?i)disk full", "(?i)no space left on device",
+	"(?i)permission denied", "(?i)access denied", "(?i)unauthorized", "(?i)forbidden",
+	`(?i)certificate (expired|verify failed)`, "(?i)handshake failure", "(?i)ssl_error",
+	`(?i)" 5\d\d `, "(?i)upstream timed out", "(?i)bad gateway", "(?i)service unavailable",
+	"(?i)circuit breaker open", "(?i)retry limit exceeded", "(?i)rate limit exceeded",
+	"(?i)stack overflow", "(?i)nullpointer", "(?i)undefined is not a function",
+	"(?i)cannot read propert(y|ies) of undefined", "(?i)unhandled rejection",
+	"(?i)assertion failed", "(?i)core dumped", "(?i)corrupt", "(?i)checksum mismatch",
+	"(?i)failed to start", "(?i)crashloop", "(?i)liveness probe failed",
+}
+
+// benchmarkLogs returns n log lines, one in a hundred of them an error.
+func benchmarkLogs(n int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "2023-03-15T12:00:%02d.%09dZ ", i%60, i)
+		switch {
+		case i%100 == 99:
+			fmt.Fprintf(&b, "ERROR request %d failed: connection refused", i)
+		case i%10 == 0:
+			fmt.Fprintf(&b, `10.0.0.%d - - "GET /api/v1/orders/%d HTTP/1.1" 200 512 "-" "curl/8.0"`, i%255, i)
+		default:
+			fmt.Fprintf(&b, "INFO worker=%d handled job %d in %dms queue=default attempt=1", i%8, i, i%300)
+		}
+	}
+	return b.Bytes()
+}
+
+// benchmarkContents returns the contents of n log lines, split and parsed
+// ahead of the benchmark loop, and their total size.
+func benchmarkContents(b *testing.B, n int) ([][]byte, int64) {
+	b.Helper()
+
+	var contents [][]byte
+	var size int64
+	for _, line := range bytes.Split(benchmarkLogs(n), []byte{'\n'}) {
+		_, content, err := logfilter.ParseLogLine(line)
+		if err != nil {
+			b.Fatal(err)
+		}
+		contents = append(contents, content)
+		size += int64(len(content))
+	}
+	return contents, size
+}
+
+// BenchmarkRegexps is the baseline of BenchmarkPatternSet_FirstMatch: every
+// pattern run on every line until one matches. It replaces the benchmark of
+// the removed FindMatchedLines path, which matched lines the same way.
+func BenchmarkRegexps(b *testing.B) {
+	patterns := compilePatterns(b, benchmarkPatterns)
+	contents, size := benchmarkContents(b, 10000)
+
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, content := range contents {
+			for _, pattern := range patterns {
+				if pattern.Match(content) {
+					break
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkPatternSet_FirstMatch(b *testing.B) {
+	set := logfilter.NewPatternSet(compilePatterns(b, benchmarkPatterns))
+	contents, size := benchmarkContents(b, 10000)
+
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, content := range contents {
+			set.FirstMatch(content)
+		}
+	}
+}
+
+func BenchmarkMatcher_Evaluate(b *testing.B) {
+	var rules []*logfilter.Rule
+	for _, pattern := range benchmarkPatterns {
+		rules = append(rules, &logfilter.Rule{Name: pattern, Patterns: compilePatterns(b, []string{pattern})})
+	}
+	matcher := &logfilter.Matcher{Rules: rules}
+	contents, size := benchmarkContents(b, 10000)
+
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, content := range contents {
+			matcher.Evaluate(logfilter.FormatText, 0, content)
+		}
+	}
+}
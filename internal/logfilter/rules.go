@@ -0,0 +1,298 @@
+package logfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule reports lines any of its patterns matches, unless one of its exclude
+// patterns matches too.
+type Rule struct {
+	Name        string
+	Patterns    []*regexp.Regexp
+	Excludes    []*regexp.Regexp
+	Severity    Severity
+	Description string
+	// Stream, when set, restricts the rule to lines of that stream.
+	Stream Stream
+	// Before and After are the numbers of lines around a match included in
+	// the alert.
+	Before int
+	After  int
+	// Threshold, when above 1, is the number of matches within Window that
+	// makes the rule fire. Counting is up to the caller; the Matcher reports
+	// every match.
+	Threshold int
+	Window    time.Duration
+}
+
+// RuleConfig defines a Rule, e.g. in a JSON rules file.
+type RuleConfig struct {
+	Name     string   `json:"name"`
+	Patterns []string `json:"patterns"`
+	Exclude  []string `json:"exclude"`
+	// CaseSensitive patterns; by default case is ignored.
+	CaseSensitive bool `json:"case_sensitive"`
+	// Severity defaults to SeverityError.
+	Severity      Severity `json:"severity"`
+	Description   string   `json:"description"`
+	ContextBefore int      `json:"context_before"`
+	ContextAfter  int      `json:"context_after"`
+	// Stream restricts the rule to a stream. A rule with a stream may omit
+	// patterns to report every line of the stream.
+	Stream Stream `json:"stream"`
+	// Threshold and Window, a duration such as "5m", make the rule fire only
+	// on threshold matches within the window.
+	Threshold int    `json:"threshold"`
+	Window    string `json:"window"`
+	// Endpoints restricts the rule to the named Docker endpoints. Applying
+	// it is up to the caller; NewRule ignores it.
+	Endpoints []string `json:"endpoints"`
+}
+
+func NewRule(config RuleConfig) (*Rule, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("rule without a name")
+	}
+	patterns := config.Patterns
+	if len(patterns) == 0 {
+		if config.Stream == 0 {
+			return nil, fmt.Errorf("rule '%s' has no patterns", config.Name)
+		}
+		patterns = []string{""}
+	}
+	if config.ContextBefore < 0 || config.ContextAfter < 0 {
+		return nil, fmt.Errorf("rule '%s' has a negative number of context lines", config.Name)
+	}
+	if config.Threshold < 0 {
+		return nil, fmt.Errorf("rule '%s' has a negative threshold", config.Name)
+	}
+
+	var window time.Duration
+	if config.Window != "" {
+		var err error
+		window, err = time.ParseDuration(config.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window '%s' of rule '%s': %w", config.Window, config.Name, err)
+		}
+	}
+	if config.Threshold > 1 && window <= 0 {
+		return nil, fmt.Errorf("rule '%s' has a threshold without a window", config.Name)
+	}
+
+	rule := &Rule{
+		Name:        config.Name,
+		Severity:    config.Severity,
+		Description: config.Description,
+		Before:      config.ContextBefore,
+		After:       config.ContextAfter,
+		Stream:      config.Stream,
+		Threshold:   config.Threshold,
+		Window:      window,
+	}
+	if rule.Severity == 0 {
+		rule.Severity = SeverityError
+	}
+
+	prefix := "(?i)"
+	if config.CaseSensitive {
+		prefix = ""
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(prefix + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' of rule '%s': %w", pattern, config.Name, err)
+		}
+		rule.Patterns = append(rule.Patterns, re)
+	}
+
+	for _, pattern := range config.Exclude {
+		re, err := regexp.Compile(prefix + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern '%s' of rule '%s': %w", pattern, config.Name, err)
+		}
+		rule.Excludes = append(rule.Excludes, re)
+	}
+
+	return rule, nil
+}
+
+// Matcher decides which log lines are errors. Lines are evaluated in this
+// order:
+//
+//  1. Lines of a structured Format are decoded. When that succeeds and there
+//     are FieldRules, the first matching field rule includes the line and
+//     Rules are not consulted.
+//  2. Otherwise Rules are tried in order, skipping rules of another stream.
+//     A rule with a matching pattern includes the line, unless one of its own
+//     excludes matches, in which case the next rule is tried.
+//  3. An included line is dropped when any of the global Excludes matches it.
+//
+// The patterns of all Rules are searched with one PatternSet, so Rules must
+// not change once lines are evaluated.
+type Matcher struct {
+	Rules      []*Rule
+	FieldRules []*FieldRule
+	Excludes   []*regexp.Regexp
+	// Format is the default format of log lines.
+	Format Format
+	// AlertFields are the fields of structured lines shown in alerts.
+	AlertFields []string
+
+	once     sync.Once
+	patterns *PatternSet
+	// offsets are the indexes of the first pattern of every rule in
+	// patterns, followed by the number of patterns.
+	offsets []int
+}
+
+// Decision explains the outcome of Matcher.Evaluate.
+type Decision struct {
+	Matched bool
+	// Format is the format the line was decoded in, FormatText when it was
+	// matched as text.
+	Format Format
+	// Rule or FieldRule is the rule that included the line, and Pattern the
+	// pattern of Rule that matched. When the line was dropped, Rule is the
+	// last rule suppressed by an exclude.
+	Rule      *Rule
+	Pattern   *regexp.Regexp
+	FieldRule *FieldRule
+	// Exclude is the exclude pattern that dropped the line, Global tells
+	// whether it is a global one.
+	Exclude *regexp.Regexp
+	Global  bool
+	// Fields are the alert fields of a structured line.
+	Fields []Field
+}
+
+// Evaluate matches the content of a log line in the given format, written to
+// the given stream. Lines of an unknown stream are matched by rules of any
+// stream.
+func (m *Matcher) Evaluate(format Format, stream Stream, content []byte) Decision {
+	d := Decision{Format: FormatText}
+
+	if format != FormatText {
+		if fields, err := ParseFields(format, content); err == nil {
+			d.Format = format
+			d.Fields = fields.Select(m.AlertFields)
+
+			if len(m.FieldRules) > 0 {
+				for _, rule := range m.FieldRules {
+					if rule.Match(fields) {
+						d.FieldRule = rule
+						return m.exclude(d, content)
+					}
+				}
+				return d
+			}
+		}
+	}
+
+	m.once.Do(m.compile)
+	var buf [4]uint64
+	candidates := m.patterns.candidates(content, buf[:0])
+
+	for r, rule := range m.Rules {
+		if rule.Stream != 0 && stream != 0 && rule.Stream != stream {
+			continue
+		}
+
+		i := m.patterns.firstMatch(candidates, m.offsets[r], m.offsets[r+1], content)
+		if i < 0 {
+			continue
+		}
+
+		d.Rule = rule
+		d.Pattern = m.patterns.patterns[i]
+		if exclude := firstMatch(rule.Excludes, content); exclude != nil {
+			d.Exclude = exclude
+			continue
+		}
+
+		d.Exclude = nil
+		return m.exclude(d, content)
+	}
+
+	return d
+}
+
+// Match reports whether the content of a log line in the default format is
+// an error.
+func (m *Matcher) Match(content []byte) bool {
+	return m.Evaluate(m.Format, 0, content).Matched
+}
+
+func (m *Matcher) compile() {
+	var patterns []*regexp.Regexp
+	for _, rule := range m.Rules {
+		m.offsets = append(m.offsets, len(patterns))
+		patterns = append(patterns, rule.Patterns...)
+	}
+	m.offsets = append(m.offsets, len(patterns))
+	m.patterns = NewPatternSet(patterns)
+}
+
+func (m *Matcher) exclude(d Decision, content []byte) Decision {
+	if exclude := firstMatch(m.Excludes, content); exclude != nil {
+		d.Exclude = exclude
+		d.Global = true
+		return d
+	}
+
+	d.Matched = true
+	return d
+}
+
+func firstMatch(patterns []*regexp.Regexp, content []byte) *regexp.Regexp {
+	for _, pattern := range patterns {
+		if pattern.Match(content) {
+			return pattern
+		}
+	}
+	return nil
+}
+
+func (d Decision) String() string {
+	var b strings.Builder
+
+	if d.Matched {
+		b.WriteString("matched")
+	} else {
+		b.WriteString("not matched")
+	}
+	fmt.Fprintf(&b, "\nformat: %s", d.Format)
+
+	switch {
+	case d.FieldRule != nil:
+		fmt.Fprintf(&b, "\ninclude: field rule %q", d.FieldRule)
+	case d.Rule != nil:
+		fmt.Fprintf(&b, "\ninclude: rule %q (%s), pattern %q", d.Rule.Name, d.Rule.Severity, d.Pattern)
+		if d.Rule.Stream != 0 {
+			fmt.Fprintf(&b, ", stream %s", d.Rule.Stream)
+		}
+		if d.Rule.Threshold > 1 {
+			fmt.Fprintf(&b, ", threshold %d in %s", d.Rule.Threshold, d.Rule.Window)
+		}
+	default:
+		b.WriteString("\ninclude: none")
+	}
+
+	if d.Exclude != nil {
+		scope := "rule"
+		if d.Global {
+			scope = "global"
+		}
+		fmt.Fprintf(&b, "\nexclude: %s pattern %q", scope, d.Exclude)
+	}
+
+	for _, field := range d.Fields {
+		fmt.Fprintf(&b, "\n%s: %q", field.Key, field.Value)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,247 @@
+package logfilter_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestMatcher_Evaluate(t *testing.T) {
+	rowsAffected := regexp.MustCompile(`(?i)\d+ rows affected`)
+	errorRule := &logfilter.Rule{
+		Name:     "errors",
+		Patterns: []*regexp.Regexp{regexp.MustCompile("(?i)ERROR")},
+		Excludes: []*regexp.Regexp{rowsAffected},
+	}
+	fatalRule := &logfilter.Rule{
+		Name:     "fatal",
+		Patterns: []*regexp.Regexp{regexp.MustCompile("(?i)FATAL"), regexp.MustCompile("(?i)panic")},
+	}
+	stderrRule := &logfilter.Rule{
+		Name:     "stderr warnings",
+		Patterns: []*regexp.Regexp{regexp.MustCompile("(?i)warn")},
+		Stream:   logfilter.StreamStderr,
+	}
+	healthcheck := regexp.MustCompile("(?i)GET /healthz")
+	levelRule, err := logfilter.ParseFieldRule("level == error")
+	if err != nil {
+		t.Fatalf("ParseFieldRule() error = %v", err)
+	}
+
+	matcher := &logfilter.Matcher{
+		Rules:       []*logfilter.Rule{errorRule, fatalRule, stderrRule},
+		FieldRules:  []*logfilter.FieldRule{levelRule},
+		Excludes:    []*regexp.Regexp{healthcheck},
+		Format:      logfilter.FormatText,
+		AlertFields: []string{"msg"},
+	}
+
+	tests := []struct {
+		name          string
+		format        logfilter.Format
+		stream        logfilter.Stream
+		content       string
+		wantMatched   bool
+		wantRule      *logfilter.Rule
+		wantFieldRule *logfilter.FieldRule
+		wantExclude   *regexp.Regexp
+		wantGlobal    bool
+	}{
+		{
+			name:        "included",
+			content:     "ERROR connection refused",
+			wantMatched: true,
+			wantRule:    errorRule,
+		},
+		{
+			name:    "no rule matches",
+			content: "INFO started",
+		},
+		{
+			name:        "suppressed by the rule exclude",
+			content:     "ERROR 0 rows affected",
+			wantRule:    errorRule,
+			wantExclude: rowsAffected,
+		},
+		{
+			name:        "rule exclude only suppresses its own rule",
+			content:     "FATAL ERROR 0 rows affected",
+			wantMatched: true,
+			wantRule:    fatalRule,
+		},
+		{
+			name:        "any pattern of a rule matches",
+			content:     "panic: nil map",
+			wantMatched: true,
+			wantRule:    fatalRule,
+		},
+		{
+			name:        "suppressed by a global exclude",
+			content:     "ERROR GET /healthz 503",
+			wantRule:    errorRule,
+			wantExclude: healthcheck,
+			wantGlobal:  true,
+		},
+		{
+			name:        "rule of the stream",
+			stream:      logfilter.StreamStderr,
+			content:     "WARN disk almost full",
+			wantMatched: true,
+			wantRule:    stderrRule,
+		},
+		{
+			name:    "rule of another stream",
+			stream:  logfilter.StreamStdout,
+			content: "WARN disk almost full",
+		},
+		{
+			name:        "rule of a stream matches lines of unknown stream",
+			content:     "WARN disk almost full",
+			wantMatched: true,
+			wantRule:    stderrRule,
+		},
+		{
+			name:          "structured line included by a field rule",
+			format:        logfilter.FormatJSON,
+			content:       `{"level":"error","msg":"payment failed"}`,
+			wantMatched:   true,
+			wantFieldRule: levelRule,
+		},
+		{
+			name:          "structured line suppressed by a global exclude",
+			format:        logfilter.FormatJSON,
+			content:       `{"level":"error","msg":"GET /healthz failed"}`,
+			wantFieldRule: levelRule,
+			wantExclude:   healthcheck,
+			wantGlobal:    true,
+		},
+		{
+			name:    "structured line not matched by field rules ignores patterns",
+			format:  logfilter.FormatJSON,
+			content: `{"level":"info","msg":"ERROR count reset"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := tt.format
+			if format == "" {
+				format = logfilter.FormatText
+			}
+
+			d := matcher.Evaluate(format, tt.stream, []byte(tt.content))
+			if d.Matched != tt.wantMatched {
+				t.Errorf("Evaluate() matched = %v, want %v\n%s", d.Matched, tt.wantMatched, d)
+			}
+			if d.Rule != tt.wantRule {
+				t.Errorf("Evaluate() rule = %v, want %v", d.Rule, tt.wantRule)
+			}
+			if d.FieldRule != tt.wantFieldRule {
+				t.Errorf("Evaluate() field rule = %v, want %v", d.FieldRule, tt.wantFieldRule)
+			}
+			if d.Exclude != tt.wantExclude {
+				t.Errorf("Evaluate() exclude = %v, want %v", d.Exclude, tt.wantExclude)
+			}
+			if d.Global != tt.wantGlobal {
+				t.Errorf("Evaluate() global = %v, want %v", d.Global, tt.wantGlobal)
+			}
+		})
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       logfilter.RuleConfig
+		content      string
+		wantMatched  bool
+		wantSeverity logfilter.Severity
+		wantErr      bool
+	}{
+		{
+			name:         "case-insensitive by default",
+			config:       logfilter.RuleConfig{Name: "errors", Patterns: []string{"error"}},
+			content:      "ERROR boom",
+			wantMatched:  true,
+			wantSeverity: logfilter.SeverityError,
+		},
+		{
+			name:         "case-sensitive",
+			config:       logfilter.RuleConfig{Name: "errors", Patterns: []string{"error"}, CaseSensitive: true, Severity: logfilter.SeverityWarning},
+			content:      "ERROR boom",
+			wantMatched:  false,
+			wantSeverity: logfilter.SeverityWarning,
+		},
+		{
+			name:         "case-sensitive exclude",
+			config:       logfilter.RuleConfig{Name: "errors", Patterns: []string{"ERROR"}, Exclude: []string{"retry"}, CaseSensitive: true},
+			content:      "ERROR Retry 1",
+			wantMatched:  true,
+			wantSeverity: logfilter.SeverityError,
+		},
+		{
+			name:    "without a name",
+			config:  logfilter.RuleConfig{Patterns: []string{"ERROR"}},
+			wantErr: true,
+		},
+		{
+			name:    "without patterns",
+			config:  logfilter.RuleConfig{Name: "errors"},
+			wantErr: true,
+		},
+		{
+			name:         "stream without patterns",
+			config:       logfilter.RuleConfig{Name: "stderr", Stream: logfilter.StreamStderr},
+			content:      "anything",
+			wantMatched:  true,
+			wantSeverity: logfilter.SeverityError,
+		},
+		{
+			name:    "invalid pattern",
+			config:  logfilter.RuleConfig{Name: "errors", Patterns: []string{"("}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid exclude pattern",
+			config:  logfilter.RuleConfig{Name: "errors", Patterns: []string{"ERROR"}, Exclude: []string{"["}},
+			wantErr: true,
+		},
+		{
+			name:    "threshold without a window",
+			config:  logfilter.RuleConfig{Name: "resets", Patterns: []string{"connection reset"}, Threshold: 100},
+			wantErr: true,
+		},
+		{
+			name:    "invalid window",
+			config:  logfilter.RuleConfig{Name: "resets", Patterns: []string{"connection reset"}, Threshold: 100, Window: "5 minutes"},
+			wantErr: true,
+		},
+		{
+			name:    "negative context",
+			config:  logfilter.RuleConfig{Name: "errors", Patterns: []string{"ERROR"}, ContextBefore: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := logfilter.NewRule(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if rule.Severity != tt.wantSeverity {
+				t.Errorf("NewRule() severity = %v, want %v", rule.Severity, tt.wantSeverity)
+			}
+
+			matcher := &logfilter.Matcher{Rules: []*logfilter.Rule{rule}, Format: logfilter.FormatText}
+			if got := matcher.Match([]byte(tt.content)); got != tt.wantMatched {
+				t.Errorf("Match() = %v, want %v", got, tt.wantMatched)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package logfilter
+
+import "unicode/utf8"
+
+const esc = 0x1b
+
+// Sanitize strips ANSI escape sequences, such as colors, and control
+// characters other than tabs from the content of a log line, and replaces
+// invalid UTF-8 with U+FFFD. Clean content is returned as is.
+func Sanitize(content []byte) []byte {
+	if isClean(content) {
+		return content
+	}
+
+	res := make([]byte, 0, len(content))
+	for i := 0; i < len(content); {
+		b := content[i]
+		switch {
+		case b == esc:
+			i = skipEscape(content, i)
+			continue
+		case b == '\t' || (b >= 0x20 && b < 0x7f):
+			res = append(res, b)
+		case b < 0x80:
+			// C0 control characters and DEL.
+		default:
+			r, size := utf8.DecodeRune(content[i:])
+			switch {
+			case r == utf8.RuneError && size == 1:
+				res = utf8.AppendRune(res, utf8.RuneError)
+			case r == 0x9b:
+				// The single character form of ESC [.
+				i = skipCSI(content, i+size)
+				continue
+			case r >= 0x80 && r <= 0x9f:
+				// C1 control characters.
+			default:
+				res = append(res, content[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	return res
+}
+
+// isClean reports whether content has nothing Sanitize removes or replaces.
+func isClean(content []byte) bool {
+	for i := 0; i < len(content); {
+		b := content[i]
+		if b < 0x80 {
+			if (b < 0x20 && b != '\t') || b == 0x7f {
+				return false
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(content[i:])
+		if (r == utf8.RuneError && size == 1) || r <= 0x9f {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// skipEscape returns the index right after the escape sequence starting at
+// i.
+func skipEscape(content []byte, i int) int {
+	if i+1 >= len(content) {
+		return len(content)
+	}
+
+	switch content[i+1] {
+	case '[':
+		return skipCSI(content, i+2)
+	case ']', 'P', 'X', '^', '_':
+		// Strings such as window titles and hyperlinks, terminated by BEL or
+		// ESC \.
+		for j := i + 2; j < len(content); j++ {
+			if content[j] == 0x07 {
+				return j + 1
+			}
+			if content[j] == esc && j+1 < len(content) && content[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(content)
+	case '(', ')', '*', '+', '-', '.', '/', '#', '%':
+		// Character set selection, followed by one more byte.
+		return min(i+3, len(content))
+	default:
+		return i + 2
+	}
+}
+
+// skipCSI returns the index right after the parameters and final byte of a
+// control sequence whose parameters start at i.
+func skipCSI(content []byte, i int) int {
+	for ; i < len(content); i++ {
+		b := content[i]
+		if b >= 0x40 && b <= 0x7e {
+			return i + 1
+		}
+		if b < 0x20 || b > 0x3f {
+			// Not a valid sequence, keep what follows.
+			return i
+		}
+	}
+	return i
+}
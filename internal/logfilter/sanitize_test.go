@@ -0,0 +1,69 @@
+package logfilter_test
+
+import (
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "clean line",
+			content: "ERROR\tcannot connect: ошибка соединения",
+			want:    "ERROR\tcannot connect: ошибка соединения",
+		},
+		{
+			name:    "colors",
+			content: "\x1b[1;31mERROR\x1b[0m cannot connect",
+			want:    "ERROR cannot connect",
+		},
+		{
+			name:    "cursor movement and erase",
+			content: "\x1b[2K\x1b[1Gprogress 100%\x1b[?25h",
+			want:    "progress 100%",
+		},
+		{
+			name:    "hyperlink",
+			content: "see \x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x07 for details",
+			want:    "see docs for details",
+		},
+		{
+			name:    "character set selection",
+			content: "\x1b(Bplain\x1b=",
+			want:    "plain",
+		},
+		{
+			name:    "control characters",
+			content: "ERROR\r\x00 boom\x07\x7f\u0085",
+			want:    "ERROR boom",
+		},
+		{
+			name:    "single character control sequence",
+			content: "\u009b31mERROR",
+			want:    "ERROR",
+		},
+		{
+			name:    "invalid UTF-8",
+			content: "ERROR \xff\xfe caf\xc3",
+			want:    "ERROR �� caf�",
+		},
+		{
+			name:    "escape at the end",
+			content: "ERROR\x1b",
+			want:    "ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(logfilter.Sanitize([]byte(tt.content))); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package logfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity int
+
+// The zero Severity is unset and sorts below every other.
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityError:    "error",
+	SeverityCritical: "critical",
+}
+
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for severity, name := range severityNames {
+		if s == name {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity '%s', expected info, warning, error or critical", s)
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// UnmarshalText allows severities by name in JSON rule definitions.
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
@@ -0,0 +1,67 @@
+package logfilter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    logfilter.Severity
+		wantErr bool
+	}{
+		{input: "info", want: logfilter.SeverityInfo},
+		{input: "Warning", want: logfilter.SeverityWarning},
+		{input: " error ", want: logfilter.SeverityError},
+		{input: "critical", want: logfilter.SeverityCritical},
+		{input: "fatal", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := logfilter.ParseSeverity(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSeverity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSeverity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity_order(t *testing.T) {
+	if !(logfilter.SeverityInfo < logfilter.SeverityWarning &&
+		logfilter.SeverityWarning < logfilter.SeverityError &&
+		logfilter.SeverityError < logfilter.SeverityCritical) {
+		t.Error("expected severities to be ordered from info to critical")
+	}
+}
+
+func TestRuleConfig_unmarshal(t *testing.T) {
+	var config logfilter.RuleConfig
+	input := `{"name":"db","patterns":["deadlock"],"exclude":["retrying"],"case_sensitive":true,"severity":"critical","description":"Database errors"}`
+	if err := json.Unmarshal([]byte(input), &config); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if config.Name != "db" || config.Severity != logfilter.SeverityCritical || !config.CaseSensitive ||
+		len(config.Patterns) != 1 || len(config.Exclude) != 1 || config.Description != "Database errors" {
+		t.Errorf("Unmarshal() = %+v", config)
+	}
+
+	if err := json.Unmarshal([]byte(`{"severity":"urgent"}`), &config); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+
+	if err := json.Unmarshal([]byte(`{"stream":"stderr"}`), &config); err != nil || config.Stream != logfilter.StreamStderr {
+		t.Errorf("Unmarshal() stream = %v, error = %v", config.Stream, err)
+	}
+	if err := json.Unmarshal([]byte(`{"stream":"stdin"}`), &config); err == nil {
+		t.Error("expected an error for an unknown stream")
+	}
+}
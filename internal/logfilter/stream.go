@@ -0,0 +1,53 @@
+package logfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stream is the output stream a log line was written to. Its values are not
+// the stream bytes of Docker frame headers; the watcher maps between them.
+type Stream int
+
+// The zero Stream is unknown. Rules without a stream match lines of any
+// stream.
+const (
+	StreamStdout Stream = iota + 1
+	StreamStderr
+	// StreamTTY is the stream of containers with a TTY, which do not tell
+	// stdout from stderr.
+	StreamTTY
+)
+
+var streamNames = map[Stream]string{
+	StreamStdout: "stdout",
+	StreamStderr: "stderr",
+	StreamTTY:    "tty",
+}
+
+func ParseStream(s string) (Stream, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for stream, name := range streamNames {
+		if s == name {
+			return stream, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown stream '%s', expected stdout, stderr or tty", s)
+}
+
+func (s Stream) String() string {
+	if name, ok := streamNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// UnmarshalText allows streams by name in JSON rule definitions.
+func (s *Stream) UnmarshalText(text []byte) error {
+	stream, err := ParseStream(string(text))
+	if err != nil {
+		return err
+	}
+	*s = stream
+	return nil
+}
@@ -0,0 +1,163 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// detector masks the matches of a pattern. When the pattern has a group
+// named "secret", only the group is masked, so that e.g. the key of a
+// key=value pair stays readable.
+type detector struct {
+	name  string
+	re    *regexp.Regexp
+	group int
+	// valid, when set, tells real secrets from look-alikes.
+	valid func([]byte) bool
+	mask  []byte
+}
+
+func newDetector(name string, re *regexp.Regexp, valid func([]byte) bool) detector {
+	return detector{
+		name:  name,
+		re:    re,
+		group: re.SubexpIndex("secret"),
+		valid: valid,
+		mask:  []byte("[REDACTED:" + name + "]"),
+	}
+}
+
+// builtins run in this order, so that e.g. a JWT is masked as such before it
+// is taken for a bearer token.
+var builtins = []detector{
+	newDetector("jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{2,}\.eyJ[A-Za-z0-9_-]{2,}\.[A-Za-z0-9_-]*`), nil),
+	newDetector("bearer", regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9._~+/-]+=*)`), nil),
+	newDetector("aws-key", regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`), nil),
+	newDetector("aws-key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`), nil),
+	newDetector("url-credentials", regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://[^\s:/@]+:(?P<secret>[^\s/@]+)@`), nil),
+	newDetector("password", regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|token)["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;&]+)`), nil),
+	newDetector("email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`), nil),
+	newDetector("card", regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), luhn),
+}
+
+// Detectors returns the names of the built-in detectors.
+func Detectors() []string {
+	var names []string
+	for i, d := range builtins {
+		if i == 0 || builtins[i-1].name != d.name {
+			names = append(names, d.name)
+		}
+	}
+	return names
+}
+
+// Redactor masks what the selected built-in detectors and custom patterns
+// find. A nil Redactor masks nothing.
+type Redactor struct {
+	detectors []detector
+}
+
+// New returns a Redactor using the named built-in detectors and custom
+// patterns. Without any, nil is returned.
+func New(detectors []string, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	selected := make(map[string]bool)
+	for _, name := range detectors {
+		found := false
+		for _, d := range builtins {
+			found = found || d.name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown redaction detector '%s', expected one of %v", name, Detectors())
+		}
+		selected[name] = true
+	}
+	for _, d := range builtins {
+		if selected[d.name] {
+			r.detectors = append(r.detectors, d)
+		}
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", pattern, err)
+		}
+		r.detectors = append(r.detectors, newDetector("custom", re, nil))
+	}
+
+	if len(r.detectors) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// Redact returns content with secrets masked. Content is returned as is when
+// there is nothing to mask.
+func (r *Redactor) Redact(content []byte) []byte {
+	if r == nil {
+		return content
+	}
+
+	for _, d := range r.detectors {
+		content = d.redact(content)
+	}
+	return content
+}
+
+// RedactString is Redact for strings.
+func (r *Redactor) RedactString(s string) string {
+	if r == nil {
+		return s
+	}
+	return string(r.Redact([]byte(s)))
+}
+
+func (d *detector) redact(content []byte) []byte {
+	var res []byte
+	last := 0
+
+	for _, m := range d.re.FindAllSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		if d.group > 0 && m[2*d.group] >= 0 {
+			start, end = m[2*d.group], m[2*d.group+1]
+		}
+		if d.valid != nil && !d.valid(content[start:end]) {
+			continue
+		}
+
+		res = append(res, content[last:start]...)
+		res = append(res, d.mask...)
+		last = end
+	}
+
+	if res == nil {
+		return content
+	}
+	return append(res, content[last:]...)
+}
+
+// luhn reports whether the digits of a card number candidate have a valid
+// Luhn checksum.
+func luhn(number []byte) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+const maxDiskOffenders = 5
+
+type DiskResource string
+
+const (
+	DiskImages     DiskResource = "images"
+	DiskVolumes    DiskResource = "volumes"
+	DiskBuildCache DiskResource = "build cache"
+)
+
+// DiskThresholds are total sizes in bytes; zero disables the check.
+type DiskThresholds struct {
+	Images     int64
+	Volumes    int64
+	BuildCache int64
+}
+
+type DiskMonitorOptions struct {
+	Interval   time.Duration
+	Thresholds DiskThresholds
+}
+
+type DiskBreach struct {
+	Host      string
+	Resource  DiskResource
+	Size      int64
+	Threshold int64
+	// Offenders are the largest items of the resource, largest first.
+	Offenders []container.DiskUsageItem
+	// Resolved is set when the size dropped back below the threshold after
+	// an alert was sent.
+	Resolved bool
+}
+
+// DiskMonitor periodically checks the space used by images, volumes and the
+// build cache of the Docker host.
+type DiskMonitor struct {
+	client   DiskClient
+	interval time.Duration
+	limits   DiskThresholds
+	C        chan *DiskBreach
+
+	alerted map[DiskResource]bool
+
+	wg sync.WaitGroup
+}
+
+func NewDiskMonitor(client DiskClient, opts *DiskMonitorOptions) *DiskMonitor {
+	return &DiskMonitor{
+		client:   client,
+		interval: opts.Interval,
+		limits:   opts.Thresholds,
+		C:        make(chan *DiskBreach),
+		alerted:  make(map[DiskResource]bool),
+	}
+}
+
+func (m *DiskMonitor) Start(parentCtx context.Context) {
+	ctx, cancel := context.WithCancel(parentCtx)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.start(ctx)
+	}()
+}
+
+func (m *DiskMonitor) start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	// Disk usage changes slowly, so check right away instead of waiting for
+	// the first tick.
+	for {
+		if err := m.check(ctx); err != nil {
+			slog.Error("Failed to check disk usage", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *DiskMonitor) check(ctx context.Context) error {
+	usage, err := m.client.DiskUsage(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get disk usage: %w", err)
+	}
+
+	m.observe(ctx, usage, DiskImages, usage.ImagesSize, m.limits.Images, usage.Images)
+	m.observe(ctx, usage, DiskVolumes, usage.VolumesSize, m.limits.Volumes, usage.Volumes)
+	m.observe(ctx, usage, DiskBuildCache, usage.BuildCacheSize, m.limits.BuildCache, usage.BuildCache)
+
+	return nil
+}
+
+// observe sends a DiskBreach when a resource grows above its threshold and a
+// resolved one when it is cleaned up below it again.
+func (m *DiskMonitor) observe(ctx context.Context, usage *container.DiskUsage, resource DiskResource, size, threshold int64, items []container.DiskUsageItem) {
+	if threshold <= 0 {
+		return
+	}
+
+	above := size > threshold
+	if above == m.alerted[resource] {
+		return
+	}
+	m.alerted[resource] = above
+
+	breach := &DiskBreach{
+		Host:      usage.Host,
+		Resource:  resource,
+		Size:      size,
+		Threshold: threshold,
+		Resolved:  !above,
+	}
+	if above {
+		breach.Offenders = items[:min(len(items), maxDiskOffenders)]
+	}
+
+	select {
+	case m.C <- breach:
+	case <-ctx.Done():
+	}
+}
+
+// Cleanup waits for the monitor to stop and closes C. The context passed to
+// Start must be canceled first.
+func (m *DiskMonitor) Cleanup() {
+	m.wg.Wait()
+	close(m.C)
+}
@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+func TestDiskMonitor_check(t *testing.T) {
+	client := NewMockStatsClient()
+
+	monitor := NewDiskMonitor(client, &DiskMonitorOptions{
+		Interval:   time.Hour,
+		Thresholds: DiskThresholds{Images: 1000, BuildCache: 100},
+	})
+	monitor.C = make(chan *DiskBreach, 10)
+
+	images := []container.DiskUsageItem{
+		{Name: "a", Size: 600}, {Name: "b", Size: 300}, {Name: "c", Size: 200},
+		{Name: "d", Size: 100}, {Name: "e", Size: 50}, {Name: "f", Size: 10},
+	}
+
+	ctx := context.Background()
+	check := func(usage container.DiskUsage) {
+		client.SetDiskUsage(&usage)
+		if err := monitor.check(ctx); err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+	}
+
+	check(container.DiskUsage{Host: "host-1", ImagesSize: 900, VolumesSize: 1 << 40, BuildCacheSize: 50})
+	if len(monitor.C) != 0 {
+		t.Fatalf("expected no alert below the thresholds, got %d", len(monitor.C))
+	}
+
+	check(container.DiskUsage{Host: "host-1", ImagesSize: 1260, BuildCacheSize: 50, Images: images})
+	if len(monitor.C) != 1 {
+		t.Fatalf("expected one alert, got %d", len(monitor.C))
+	}
+
+	breach := <-monitor.C
+	if breach.Resource != DiskImages || breach.Resolved || breach.Size != 1260 || breach.Threshold != 1000 || breach.Host != "host-1" {
+		t.Errorf("unexpected breach: %+v", breach)
+	}
+	if len(breach.Offenders) != maxDiskOffenders || breach.Offenders[0].Name != "a" {
+		t.Errorf("expected the %d largest images, got %+v", maxDiskOffenders, breach.Offenders)
+	}
+
+	check(container.DiskUsage{Host: "host-1", ImagesSize: 1300, BuildCacheSize: 50, Images: images})
+	if len(monitor.C) != 0 {
+		t.Fatalf("expected no repeated alert, got %d", len(monitor.C))
+	}
+
+	check(container.DiskUsage{Host: "host-1", ImagesSize: 400, BuildCacheSize: 50})
+	if len(monitor.C) != 1 {
+		t.Fatalf("expected a resolved alert, got %d", len(monitor.C))
+	}
+	if breach := <-monitor.C; !breach.Resolved || breach.Resource != DiskImages || breach.Offenders != nil {
+		t.Errorf("expected resolved images breach, got %+v", breach)
+	}
+}
+
+func TestDiskMonitor_check_error(t *testing.T) {
+	client := NewMockStatsClient()
+	client.SetDiskUsageError(errors.New("connection refused"))
+
+	monitor := NewDiskMonitor(client, &DiskMonitorOptions{
+		Interval:   time.Hour,
+		Thresholds: DiskThresholds{Images: 1000},
+	})
+
+	if err := monitor.check(context.Background()); err == nil {
+		t.Error("expected an error")
+	}
+}
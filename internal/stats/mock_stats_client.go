@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+// MockStatsClient is a mock implementation of StatsClient and DiskClient for testing
+type MockStatsClient struct {
+	mu             sync.Mutex
+	containers     []container.Container
+	containersErr  error
+	stats          map[string]*container.Stats
+	statsCallCount int
+	diskUsage      *container.DiskUsage
+	diskUsageErr   error
+}
+
+// NewMockStatsClient creates a new MockStatsClient
+func NewMockStatsClient() *MockStatsClient {
+	return &MockStatsClient{
+		stats: make(map[string]*container.Stats),
+	}
+}
+
+// SetContainers sets the containers to be returned by RunningContainers
+func (m *MockStatsClient) SetContainers(containers []container.Container) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containers = containers
+}
+
+// SetContainersError sets the error to be returned by RunningContainers
+func (m *MockStatsClient) SetContainersError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containersErr = err
+}
+
+// SetStats sets the stats to be returned by ContainerStats for a specific container
+func (m *MockStatsClient) SetStats(id string, stats *container.Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats[id] = stats
+}
+
+// SetDiskUsage sets the usage to be returned by DiskUsage
+func (m *MockStatsClient) SetDiskUsage(usage *container.DiskUsage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diskUsage = usage
+}
+
+// SetDiskUsageError sets the error to be returned by DiskUsage
+func (m *MockStatsClient) SetDiskUsageError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diskUsageErr = err
+}
+
+// RunningContainers implements StatsClient.RunningContainers
+func (m *MockStatsClient) RunningContainers(ctx context.Context) ([]container.Container, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.containers, m.containersErr
+}
+
+// ContainerStats implements StatsClient.ContainerStats
+func (m *MockStatsClient) ContainerStats(ctx context.Context, id string) (*container.Stats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statsCallCount++
+	stats, ok := m.stats[id]
+	if !ok {
+		return nil, fmt.Errorf("no stats for container %s", id)
+	}
+	copied := *stats
+	return &copied, nil
+}
+
+// DiskUsage implements DiskClient.DiskUsage
+func (m *MockStatsClient) DiskUsage(ctx context.Context) (*container.DiskUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.diskUsageErr != nil {
+		return nil, m.diskUsageErr
+	}
+	if m.diskUsage == nil {
+		return &container.DiskUsage{}, nil
+	}
+	copied := *m.diskUsage
+	return &copied, nil
+}
+
+// StatsCallCount returns the number of times ContainerStats was called
+func (m *MockStatsClient) StatsCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statsCallCount
+}
@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+const maxConcurrentSamples = 8
+
+type Metric string
+
+const (
+	MetricCPU    Metric = "cpu"
+	MetricMemory Metric = "memory"
+	MetricPids   Metric = "pids"
+)
+
+type Thresholds struct {
+	// CPUPercent is relative to a single CPU, so 200 means two full cores.
+	CPUPercent float64
+	// MemoryPercent is relative to the container memory limit.
+	MemoryPercent float64
+	Pids          uint64
+}
+
+type MonitorOptions struct {
+	Interval   time.Duration
+	Thresholds Thresholds
+	// For is how long a threshold must stay breached before an alert is sent.
+	For time.Duration
+}
+
+type Breach struct {
+	Container container.Container
+	Metric    Metric
+	Value     float64
+	Threshold float64
+	// Since is when the threshold was first seen breached.
+	Since time.Time
+	Stats container.Stats
+	// Resolved is set when the value dropped back below the threshold after
+	// an alert was sent.
+	Resolved bool
+}
+
+type breachState struct {
+	since   time.Time
+	alerted bool
+}
+
+type Monitor struct {
+	client   StatsClient
+	interval time.Duration
+	limits   Thresholds
+	sustain  time.Duration
+	now      func() time.Time
+	C        chan *Breach
+
+	mu       sync.Mutex
+	breaches map[string]map[Metric]*breachState
+
+	wg sync.WaitGroup
+}
+
+func New(client StatsClient, opts *MonitorOptions) *Monitor {
+	return &Monitor{
+		client:   client,
+		interval: opts.Interval,
+		limits:   opts.Thresholds,
+		sustain:  opts.For,
+		now:      time.Now,
+		C:        make(chan *Breach),
+		breaches: make(map[string]map[Metric]*breachState),
+	}
+}
+
+func (m *Monitor) Start(parentCtx context.Context) {
+	ctx, cancel := context.WithCancel(parentCtx)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel()
+		m.start(ctx)
+	}()
+}
+
+func (m *Monitor) start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.check(ctx); err != nil {
+				slog.Error("Failed to check container stats", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check samples every running container and evaluates the thresholds.
+func (m *Monitor) check(ctx context.Context) error {
+	containers, err := m.client.RunningContainers(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to list containers: %w", err)
+	}
+
+	m.forgetStopped(containers)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentSamples)
+
+	for _, c := range containers {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(c container.Container) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stats, err := m.client.ContainerStats(ctx, c.ID)
+			if err != nil {
+				slog.Error("Failed to get container stats", "containerID", c.ID, "err", err)
+				return
+			}
+
+			m.evaluate(ctx, c, stats)
+		}(c)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (m *Monitor) evaluate(ctx context.Context, c container.Container, stats *container.Stats) {
+	if m.limits.CPUPercent > 0 {
+		m.observe(ctx, c, stats, MetricCPU, stats.CPUPercent, m.limits.CPUPercent)
+	}
+
+	if m.limits.MemoryPercent > 0 && stats.MemoryLimit > 0 {
+		m.observe(ctx, c, stats, MetricMemory, stats.MemoryPercent, m.limits.MemoryPercent)
+	}
+
+	if m.limits.Pids > 0 {
+		m.observe(ctx, c, stats, MetricPids, float64(stats.Pids), float64(m.limits.Pids))
+	}
+}
+
+// observe tracks how long a metric has been above its threshold. A Breach is
+// sent once it has stayed above for the sustain period, and a resolved Breach
+// when it drops back below afterwards.
+func (m *Monitor) observe(ctx context.Context, c container.Container, stats *container.Stats, metric Metric, value, threshold float64) {
+	now := m.now()
+
+	m.mu.Lock()
+	metrics, ok := m.breaches[c.ID]
+	if !ok {
+		metrics = make(map[Metric]*breachState)
+		m.breaches[c.ID] = metrics
+	}
+	state := metrics[metric]
+
+	var breach *Breach
+	switch {
+	case value > threshold && state == nil:
+		state = &breachState{since: now}
+		metrics[metric] = state
+		fallthrough
+	case value > threshold && !state.alerted:
+		if now.Sub(state.since) >= m.sustain {
+			state.alerted = true
+			breach = &Breach{Since: state.since}
+		}
+	case value <= threshold && state != nil:
+		delete(metrics, metric)
+		if state.alerted {
+			breach = &Breach{Since: state.since, Resolved: true}
+		}
+	}
+	m.mu.Unlock()
+
+	if breach == nil {
+		return
+	}
+
+	breach.Container = c
+	breach.Metric = metric
+	breach.Value = value
+	breach.Threshold = threshold
+	breach.Stats = *stats
+
+	select {
+	case m.C <- breach:
+	case <-ctx.Done():
+	}
+}
+
+func (m *Monitor) forgetStopped(containers []container.Container) {
+	running := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		running[c.ID] = true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range m.breaches {
+		if !running[id] {
+			delete(m.breaches, id)
+		}
+	}
+}
+
+// Cleanup waits for the monitor to stop and closes C. The context passed to
+// Start must be canceled first.
+func (m *Monitor) Cleanup() {
+	m.wg.Wait()
+	close(m.C)
+}
@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+func TestMonitor_check(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockStatsClient()
+	client.SetContainers([]container.Container{c})
+
+	monitor := New(client, &MonitorOptions{
+		Interval:   time.Second,
+		Thresholds: Thresholds{CPUPercent: 80, MemoryPercent: 90, Pids: 100},
+		For:        time.Minute,
+	})
+	monitor.C = make(chan *Breach, 10)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	monitor.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	check := func(stats container.Stats) {
+		client.SetStats(c.ID, &stats)
+		if err := monitor.check(ctx); err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+	}
+
+	check(container.Stats{CPUPercent: 95, MemoryPercent: 50, MemoryLimit: 1024, Pids: 10})
+	if len(monitor.C) != 0 {
+		t.Fatalf("expected no alert before the duration elapsed, got %d", len(monitor.C))
+	}
+
+	now = now.Add(time.Minute)
+	check(container.Stats{CPUPercent: 90, MemoryPercent: 50, MemoryLimit: 1024, Pids: 10})
+	if len(monitor.C) != 1 {
+		t.Fatalf("expected one alert, got %d", len(monitor.C))
+	}
+
+	breach := <-monitor.C
+	if breach.Metric != MetricCPU || breach.Resolved || breach.Value != 90 || breach.Threshold != 80 {
+		t.Errorf("unexpected breach: %+v", breach)
+	}
+	if !breach.Since.Equal(now.Add(-time.Minute)) {
+		t.Errorf("expected breach to start at the first sample, got %v", breach.Since)
+	}
+
+	now = now.Add(time.Minute)
+	check(container.Stats{CPUPercent: 99, MemoryPercent: 50, MemoryLimit: 1024, Pids: 10})
+	if len(monitor.C) != 0 {
+		t.Fatalf("expected no repeated alert, got %d", len(monitor.C))
+	}
+
+	check(container.Stats{CPUPercent: 10, MemoryPercent: 50, MemoryLimit: 1024, Pids: 10})
+	if len(monitor.C) != 1 {
+		t.Fatalf("expected a resolved alert, got %d", len(monitor.C))
+	}
+	if breach := <-monitor.C; !breach.Resolved || breach.Metric != MetricCPU {
+		t.Errorf("expected resolved cpu breach, got %+v", breach)
+	}
+}
+
+func TestMonitor_check_shortSpike(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockStatsClient()
+	client.SetContainers([]container.Container{c})
+
+	monitor := New(client, &MonitorOptions{
+		Interval:   time.Second,
+		Thresholds: Thresholds{Pids: 100},
+		For:        time.Minute,
+	})
+	monitor.C = make(chan *Breach, 10)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	monitor.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for _, pids := range []uint64{150, 50, 150} {
+		client.SetStats(c.ID, &container.Stats{Pids: pids})
+		if err := monitor.check(ctx); err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+		now = now.Add(40 * time.Second)
+	}
+
+	if len(monitor.C) != 0 {
+		t.Fatalf("expected no alert for short spikes, got %d", len(monitor.C))
+	}
+}
+
+func TestMonitor_check_forgetsStoppedContainers(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockStatsClient()
+	client.SetContainers([]container.Container{c})
+	client.SetStats(c.ID, &container.Stats{CPUPercent: 95})
+
+	monitor := New(client, &MonitorOptions{
+		Interval:   time.Second,
+		Thresholds: Thresholds{CPUPercent: 80},
+	})
+	monitor.C = make(chan *Breach, 10)
+
+	ctx := context.Background()
+	if err := monitor.check(ctx); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if len(monitor.C) != 1 {
+		t.Fatalf("expected one alert, got %d", len(monitor.C))
+	}
+
+	client.SetContainers(nil)
+	if err := monitor.check(ctx); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if len(monitor.breaches) != 0 {
+		t.Errorf("expected state of stopped containers to be dropped, got %v", monitor.breaches)
+	}
+}
+
+func TestMonitor_check_skipsMemoryWithoutLimit(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockStatsClient()
+	client.SetContainers([]container.Container{c})
+	client.SetStats(c.ID, &container.Stats{MemoryPercent: 100})
+
+	monitor := New(client, &MonitorOptions{
+		Interval:   time.Second,
+		Thresholds: Thresholds{MemoryPercent: 90},
+	})
+	monitor.C = make(chan *Breach, 10)
+
+	if err := monitor.check(context.Background()); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if len(monitor.C) != 0 {
+		t.Errorf("expected no alert without a memory limit, got %d", len(monitor.C))
+	}
+}
@@ -0,0 +1,16 @@
+package stats
+
+import (
+	"context"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+type StatsClient interface {
+	RunningContainers(ctx context.Context) ([]container.Container, error)
+	ContainerStats(ctx context.Context, id string) (*container.Stats, error)
+}
+
+type DiskClient interface {
+	DiskUsage(ctx context.Context) (*container.DiskUsage, error)
+}
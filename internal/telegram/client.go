@@ -27,10 +27,20 @@ func New(token, chatID string, client *http.Client) *Client {
 }
 
 func (c *Client) SendMessage(ctx context.Context, message string) error {
+	return c.SendMessageTo(ctx, c.chatID, message)
+}
+
+// SendMessageTo sends the message to another chat than the default one. An
+// empty chatID selects the default chat.
+func (c *Client) SendMessageTo(ctx context.Context, chatID, message string) error {
+	if chatID == "" {
+		chatID = c.chatID
+	}
+
 	url := fmt.Sprintf(telegramAPIURL, c.token)
 
 	requestBody, err := json.Marshal(MessageRequest{
-		ChatID: c.chatID,
+		ChatID: chatID,
 		Text:   message,
 	})
 	if err != nil {
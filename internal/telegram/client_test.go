@@ -163,3 +163,46 @@ func TestSendMessage_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestSendMessageTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		chatID     string
+		wantChatID string
+	}{
+		{name: "other chat", chatID: "critical-chat-id", wantChatID: "critical-chat-id"},
+		{name: "default chat", chatID: "", wantChatID: "test-chat-id"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var msgReq MessageRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&msgReq); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer server.Close()
+
+			client := &Client{
+				token:  "test-token",
+				chatID: "test-chat-id",
+				client: &http.Client{
+					Transport: &transportWithURLOverride{
+						base:      server.Client().Transport,
+						serverURL: server.URL,
+					},
+				},
+			}
+
+			if err := client.SendMessageTo(context.Background(), tc.chatID, "test message"); err != nil {
+				t.Fatalf("SendMessageTo failed: %v", err)
+			}
+
+			if msgReq.ChatID != tc.wantChatID {
+				t.Errorf("expected ChatID %s, got %s", tc.wantChatID, msgReq.ChatID)
+			}
+		})
+	}
+}
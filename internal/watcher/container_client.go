@@ -4,10 +4,18 @@ import (
 	"context"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 )
 
 type ContainerClient interface {
 	RunningContainers(ctx context.Context) ([]container.Container, error)
-	ContainerLogs(ctx context.Context, id, since string, tail int) ([]byte, error)
+	ContainerLogs(ctx context.Context, id, since string, tail int) (*docker.LogStream, error)
+	ContainerInspect(ctx context.Context, id string) (*container.Container, error)
+	ContainerState(ctx context.Context, id string) (*container.State, error)
+	FollowLogs(ctx context.Context, id, since string) (*docker.LogStream, error)
+	ContainerEvents(ctx context.Context) (<-chan container.Event, <-chan error)
+	Services(ctx context.Context) ([]container.Service, error)
+	ServiceTasks(ctx context.Context, serviceID string) ([]container.Task, error)
+	FollowServiceLogs(ctx context.Context, serviceID, since string) (*docker.LogStream, error)
 	Close() error
 }
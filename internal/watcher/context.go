@@ -0,0 +1,211 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// ContextLine is a line of the context of a match.
+type ContextLine struct {
+	Line *logfilter.MatchedLine
+	// Matched tells the lines that matched a rule themselves.
+	Matched bool
+}
+
+// matchCollector evaluates the lines of one container in one log read, in
+// order, and sends the matches with the context lines their rules ask for.
+// A match within the after context of the previous one joins its group, so
+// that overlapping context windows end up in one alert and no line is
+// reported twice.
+type matchCollector struct {
+	w         *Watcher
+	container container.Container
+	// source is the ID of the container or service the logs were read from,
+	// which keeps the threshold counts.
+	source    string
+	maxBefore int
+	// aggregator groups multi-line events before they are evaluated.
+	aggregator *logfilter.Aggregator
+
+	// recent are the last lines not part of any group.
+	recent []ContextLine
+	group  *matchGroup
+}
+
+type matchGroup struct {
+	match *MatchedLog
+	lines []ContextLine
+	// remaining is the number of lines still to collect after the last match.
+	remaining int
+}
+
+func (w *Watcher) newMatchCollector(container container.Container, source string) *matchCollector {
+	c := &matchCollector{w: w, container: container, source: source}
+	for _, rule := range w.matcher.Rules {
+		c.maxBefore = max(c.maxBefore, rule.Before)
+	}
+	if w.multiline != nil {
+		c.aggregator = logfilter.NewAggregator(w.multiline)
+	}
+	return c
+}
+
+// withContext reports whether any rule asks for context lines.
+func (w *Watcher) withContext() bool {
+	for _, rule := range w.matcher.Rules {
+		if rule.Before > 0 || rule.After > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// feed adds the next line, grouped into multi-line events first when
+// enabled.
+func (c *matchCollector) feed(ctx context.Context, line *logfilter.MatchedLine) error {
+	if c.aggregator != nil {
+		return c.add(ctx, c.aggregator.Add(line.Stream, line.Timestamp, line.Content))
+	}
+	return c.add(ctx, line)
+}
+
+// flushPending evaluates the pending multi-line event, if any, and sends the
+// pending group.
+func (c *matchCollector) flushPending(ctx context.Context) error {
+	if c.aggregator != nil {
+		if err := c.add(ctx, c.aggregator.Flush()); err != nil {
+			return err
+		}
+	}
+	return c.flush(ctx)
+}
+
+// add evaluates the next line. A nil line is ignored.
+func (c *matchCollector) add(ctx context.Context, line *logfilter.MatchedLine) error {
+	if line == nil {
+		return nil
+	}
+
+	decision := c.w.Evaluate(c.container, line.Stream, line.Content)
+	if !decision.Matched {
+		if c.group == nil && c.maxBefore == 0 {
+			return nil
+		}
+		return c.addLine(ctx, ContextLine{Line: cloneLine(line)})
+	}
+
+	m := &MatchedLog{
+		Container: c.container,
+		Line:      cloneLine(line),
+		Rule:      decision.Rule,
+	}
+	m.Line.Fields = decision.Fields
+
+	// A match below its threshold is kept as a sample in the threshold
+	// state and as a context line of its own, each redacted once when sent.
+	if !c.w.crossesThreshold(c.source, m) {
+		return c.addLine(ctx, ContextLine{Line: cloneLine(line)})
+	}
+
+	c.w.redactLine(m.Line)
+	for _, sample := range m.Samples {
+		c.w.redactLine(sample)
+	}
+
+	// Crash loops are detected for watched containers, not service tasks.
+	if c.w.crashLoopThreshold > 0 && c.container.ID == c.source {
+		c.w.rememberError(c.container.ID, m.Line)
+	}
+
+	var before, after int
+	if decision.Rule != nil {
+		before, after = decision.Rule.Before, decision.Rule.After
+	}
+
+	if c.group != nil {
+		c.group.lines = append(c.group.lines, ContextLine{Line: m.Line, Matched: true})
+		c.group.remaining = max(c.group.remaining-1, after)
+		if m.Severity() > c.group.match.Severity() {
+			c.group.match = m
+		}
+		if c.group.remaining == 0 {
+			return c.flush(ctx)
+		}
+		return nil
+	}
+
+	if before == 0 && after == 0 {
+		c.remember(ContextLine{Line: m.Line, Matched: true})
+		return c.w.send(ctx, m)
+	}
+
+	group := &matchGroup{match: m, remaining: after}
+	group.lines = append(group.lines, c.recent[max(len(c.recent)-before, 0):]...)
+	group.lines = append(group.lines, ContextLine{Line: m.Line, Matched: true})
+	c.recent = nil
+	c.group = group
+
+	if after == 0 {
+		return c.flush(ctx)
+	}
+	return nil
+}
+
+// addLine adds a line that did not match to the pending group, or keeps it
+// as context for the next match.
+func (c *matchCollector) addLine(ctx context.Context, line ContextLine) error {
+	if c.group == nil {
+		c.remember(line)
+		return nil
+	}
+
+	c.group.lines = append(c.group.lines, line)
+	c.group.remaining--
+	if c.group.remaining == 0 {
+		return c.flush(ctx)
+	}
+	return nil
+}
+
+func (c *matchCollector) remember(line ContextLine) {
+	if c.maxBefore == 0 {
+		return
+	}
+
+	c.recent = append(c.recent, line)
+	if len(c.recent) > c.maxBefore {
+		c.recent = c.recent[len(c.recent)-c.maxBefore:]
+	}
+}
+
+// flush sends the pending group, even if its after context is incomplete.
+func (c *matchCollector) flush(ctx context.Context) error {
+	group := c.group
+	if group == nil {
+		return nil
+	}
+	c.group = nil
+
+	// Matches are redacted once they cross their threshold, context lines
+	// only once they are sent.
+	for _, line := range group.lines {
+		if !line.Matched {
+			c.w.redactLine(line.Line)
+		}
+	}
+
+	group.match.Context = group.lines
+	return c.w.send(ctx, group.match)
+}
+
+func cloneLine(line *logfilter.MatchedLine) *logfilter.MatchedLine {
+	return &logfilter.MatchedLine{
+		Timestamp: bytes.Clone(line.Timestamp),
+		Content:   bytes.Clone(line.Content),
+		Stream:    line.Stream,
+		Omitted:   line.Omitted,
+	}
+}
@@ -0,0 +1,124 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestWatcher_processContainerLogs_context(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []logfilter.RuleConfig
+		lines []string
+		// want holds the context of every match, matched lines marked by ">".
+		want         [][]string
+		wantSeverity []logfilter.Severity
+	}{
+		{
+			name:  "lines before and after",
+			rules: []logfilter.RuleConfig{{Name: "errors", Patterns: []string{"ERROR"}, ContextBefore: 2, ContextAfter: 1}},
+			lines: []string{"one", "two", "three", "ERROR four", "five", "six"},
+			want:  [][]string{{"two", "three", "> ERROR four", "five"}},
+		},
+		{
+			name:  "overlapping windows merge",
+			rules: []logfilter.RuleConfig{{Name: "errors", Patterns: []string{"ERROR"}, ContextBefore: 1, ContextAfter: 2}},
+			lines: []string{"one", "ERROR two", "three", "ERROR four", "five", "six", "seven"},
+			want:  [][]string{{"one", "> ERROR two", "three", "> ERROR four", "five", "six"}},
+		},
+		{
+			name:  "separate windows do not repeat lines",
+			rules: []logfilter.RuleConfig{{Name: "errors", Patterns: []string{"ERROR"}, ContextBefore: 2, ContextAfter: 1}},
+			lines: []string{"ERROR one", "two", "three", "ERROR four"},
+			want:  [][]string{{"> ERROR one", "two"}, {"three", "> ERROR four"}},
+		},
+		{
+			name: "merged group takes the most severe match",
+			rules: []logfilter.RuleConfig{
+				{Name: "warnings", Patterns: []string{"WARN"}, Severity: logfilter.SeverityWarning, ContextAfter: 1},
+				{Name: "panics", Patterns: []string{"panic"}, Severity: logfilter.SeverityCritical},
+			},
+			lines:        []string{"WARN one", "panic: two", "three"},
+			want:         [][]string{{"> WARN one", "> panic: two"}},
+			wantSeverity: []logfilter.Severity{logfilter.SeverityCritical},
+		},
+		{
+			name: "match without context before a window",
+			rules: []logfilter.RuleConfig{
+				{Name: "fatal", Patterns: []string{"FATAL"}},
+				{Name: "errors", Patterns: []string{"ERROR"}, ContextBefore: 2},
+			},
+			lines: []string{"one", "FATAL two", "ERROR three"},
+			want:  [][]string{nil, {"one", "> FATAL two", "> ERROR three"}},
+		},
+		{
+			name:  "stream ends before the after context is complete",
+			rules: []logfilter.RuleConfig{{Name: "errors", Patterns: []string{"ERROR"}, ContextAfter: 5}},
+			lines: []string{"one", "ERROR two", "three"},
+			want:  [][]string{{"> ERROR two", "three"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := container.Container{ID: "container1", Name: "test-container"}
+
+			logs := make([]string, len(tt.lines))
+			for i, line := range tt.lines {
+				logs[i] = fmt.Sprintf("2023-03-15T12:00:%02d.000000000Z %s", i+1, line)
+			}
+
+			client := NewMockContainerClient()
+			client.SetLogs(c.ID, []byte(strings.Join(logs, "\n")))
+
+			matcher, err := newMatcher(&WatcherOptions{Rules: tt.rules})
+			if err != nil {
+				t.Fatalf("failed to compile rules: %v", err)
+			}
+
+			watcher := &Watcher{
+				client:  client,
+				matcher: matcher,
+				offsets: map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+				C:       make(chan *MatchedLog, 10),
+			}
+
+			if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+				t.Fatalf("processContainerLogs failed: %v", err)
+			}
+			close(watcher.C)
+
+			var got []*MatchedLog
+			for match := range watcher.C {
+				got = append(got, match)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d matches, got %d", len(tt.want), len(got))
+			}
+
+			for i, match := range got {
+				var context []string
+				for _, line := range match.Context {
+					text := string(line.Line.Content)
+					if line.Matched {
+						text = "> " + text
+					}
+					context = append(context, text)
+				}
+
+				if strings.Join(context, "|") != strings.Join(tt.want[i], "|") {
+					t.Errorf("match %d: expected context %q, got %q", i, tt.want[i], context)
+				}
+
+				if i < len(tt.wantSeverity) && match.Severity() != tt.wantSeverity[i] {
+					t.Errorf("match %d: expected severity %s, got %s", i, tt.wantSeverity[i], match.Severity())
+				}
+			}
+		})
+	}
+}
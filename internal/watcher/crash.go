@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+type Crash struct {
+	Container    container.Container
+	ExitCode     int
+	OOMKilled    bool
+	RestartCount int
+	Lines        []*logfilter.MatchedLine
+}
+
+// exitReason collects what happened to a container before its die event.
+type exitReason struct {
+	oomKilled bool
+	killed    bool
+}
+
+func (w *Watcher) takeExitReason(id string) exitReason {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	reason := w.exits[id]
+	delete(w.exits, id)
+	return reason
+}
+
+// reportCrash sends a Crash when a watched container exits with a non-zero
+// exit code or is OOM-killed. Containers stopped on request, which receive a
+// kill event right before they die, are not reported unless the kernel killed
+// them for running out of memory.
+func (w *Watcher) reportCrash(ctx context.Context, c container.Container, event container.Event, reason exitReason) {
+	crash := &Crash{
+		Container: c,
+		OOMKilled: reason.oomKilled,
+	}
+
+	exitCode, err := strconv.Atoi(event.Attributes["exitCode"])
+	hasExitCode := err == nil
+	crash.ExitCode = exitCode
+
+	state, err := w.client.ContainerState(ctx, c.ID)
+	if err != nil {
+		slog.Error("Failed to inspect stopped container", "containerID", c.ID, "err", err)
+	} else {
+		if !hasExitCode {
+			crash.ExitCode = state.ExitCode
+		}
+		crash.OOMKilled = crash.OOMKilled || state.OOMKilled
+		crash.RestartCount = state.RestartCount
+	}
+
+	if !crash.OOMKilled && (crash.ExitCode == 0 || reason.killed) {
+		return
+	}
+
+	lines, err := w.lastLines(ctx, c.ID, w.crashLogLines)
+	if err != nil {
+		slog.Error("Failed to get last log lines", "containerID", c.ID, "err", err)
+	}
+	crash.Lines = lines
+
+	select {
+	case w.Crashes <- crash:
+	case <-ctx.Done():
+	}
+}
+
+// lastLines returns the last n log lines of the container.
+func (w *Watcher) lastLines(ctx context.Context, id string, n int) ([]*logfilter.MatchedLine, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	logs, err := w.client.ContainerLogs(ctx, id, "", n)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get logs for container %s: %w", id, err)
+	}
+
+	defer logs.Close()
+
+	lines := make([]*logfilter.MatchedLine, 0, n)
+	scanner := w.newLogScanner(id, logs)
+	for scanner.Scan() {
+		timestamp, content, err := w.parseLogLine(scanner.Line())
+		if err != nil || timestamp == nil {
+			continue
+		}
+
+		line := &logfilter.MatchedLine{
+			Timestamp: bytes.Clone(timestamp),
+			Content:   bytes.Clone(content),
+			Stream:    scanner.logStream(),
+		}
+		w.redactLine(line)
+		lines = append(lines, line)
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines, scanner.Err()
+}
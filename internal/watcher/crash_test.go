@@ -0,0 +1,128 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+func TestWatcher_reportCrash(t *testing.T) {
+	tests := []struct {
+		name              string
+		attributes        map[string]string
+		state             *container.State
+		before            []string
+		expectedCrash     bool
+		expectedExitCode  int
+		expectedOOMKilled bool
+	}{
+		{
+			name:             "non-zero exit code",
+			attributes:       map[string]string{"exitCode": "1"},
+			state:            &container.State{ExitCode: 1, RestartCount: 2},
+			expectedCrash:    true,
+			expectedExitCode: 1,
+		},
+		{
+			name:          "clean exit",
+			attributes:    map[string]string{"exitCode": "0"},
+			state:         &container.State{},
+			expectedCrash: false,
+		},
+		{
+			name:          "stopped on request",
+			attributes:    map[string]string{"exitCode": "143"},
+			state:         &container.State{ExitCode: 143},
+			before:        []string{container.EventKill},
+			expectedCrash: false,
+		},
+		{
+			name:              "oom killed",
+			attributes:        map[string]string{"exitCode": "137"},
+			state:             &container.State{ExitCode: 137},
+			before:            []string{container.EventOOM, container.EventKill},
+			expectedCrash:     true,
+			expectedExitCode:  137,
+			expectedOOMKilled: true,
+		},
+		{
+			name:              "exit code from inspect",
+			attributes:        map[string]string{},
+			state:             &container.State{ExitCode: 2, OOMKilled: true},
+			expectedCrash:     true,
+			expectedExitCode:  2,
+			expectedOOMKilled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := container.Container{ID: "container1", Name: "test-container"}
+
+			client := NewMockContainerClient()
+			client.SetState(c.ID, tt.state)
+			client.SetLogs(c.ID, []byte(
+				"2023-03-15T12:01:00.000000000Z starting\n"+
+					"2023-03-15T12:02:00.000000000Z panic: boom\n",
+			))
+
+			watcher, err := New(client, &WatcherOptions{
+				Interval:      time.Second,
+				ErrorPatterns: []string{"ERROR"},
+				CrashAlerts:   true,
+				CrashLogLines: 10,
+			})
+			if err != nil {
+				t.Fatalf("failed to create watcher: %v", err)
+			}
+			watcher.C = make(chan *MatchedLog, 10)
+			watcher.Crashes = make(chan *Crash, 1)
+			watcher.containers[c.ID] = c
+			watcher.offsets[c.ID] = "2023-03-15T12:00:00.000000000Z"
+
+			ctx := context.Background()
+			for _, action := range tt.before {
+				watcher.handleEvent(ctx, container.Event{Action: action, Container: c})
+			}
+			watcher.handleEvent(ctx, container.Event{
+				Action:     container.EventDie,
+				Container:  c,
+				Attributes: tt.attributes,
+			})
+
+			var crash *Crash
+			select {
+			case crash = <-watcher.Crashes:
+			default:
+			}
+
+			if !tt.expectedCrash {
+				if crash != nil {
+					t.Errorf("expected no crash, got %+v", crash)
+				}
+				return
+			}
+
+			if crash == nil {
+				t.Fatal("expected a crash, got none")
+			}
+			if crash.ExitCode != tt.expectedExitCode {
+				t.Errorf("expected exit code %d, got %d", tt.expectedExitCode, crash.ExitCode)
+			}
+			if crash.OOMKilled != tt.expectedOOMKilled {
+				t.Errorf("expected OOMKilled %v, got %v", tt.expectedOOMKilled, crash.OOMKilled)
+			}
+			if crash.RestartCount != tt.state.RestartCount {
+				t.Errorf("expected restart count %d, got %d", tt.state.RestartCount, crash.RestartCount)
+			}
+			if len(crash.Lines) != 2 || string(crash.Lines[1].Content) != "panic: boom" {
+				t.Errorf("expected last log lines, got %d lines", len(crash.Lines))
+			}
+			if _, ok := watcher.exits[c.ID]; ok {
+				t.Error("expected exit reason to be cleared")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+const maxRecentErrors = 5
+
+type CrashLoop struct {
+	Container container.Container
+	// Restarts is the number of restarts within Window.
+	Restarts int
+	Window   time.Duration
+	// RestartCount is the total restart count reported by the daemon.
+	RestartCount int
+	// Update is set for the periodic alerts sent while the loop continues.
+	Update bool
+	Lines  []*logfilter.MatchedLine
+}
+
+type loopState struct {
+	restarts  []time.Time
+	looping   bool
+	alertedAt time.Time
+}
+
+// recordDeath remembers that a watched container died on its own, so that
+// its next start counts as a restart. The caller must hold w.mu.
+func (w *Watcher) recordDeath(id string) {
+	if _, ok := w.loops[id]; !ok {
+		w.loops[id] = &loopState{}
+	}
+}
+
+// recordRestart counts a restart of a container that died before and sends a
+// CrashLoop once the restart threshold is crossed within the window. While the
+// loop continues, an update is sent at most once per update interval.
+func (w *Watcher) recordRestart(ctx context.Context, c container.Container, at time.Time) {
+	w.mu.Lock()
+	state, ok := w.loops[c.ID]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+
+	state.restarts = append(state.restarts, at)
+	cutoff := at.Add(-w.crashLoopWindow)
+	for len(state.restarts) > 0 && state.restarts[0].Before(cutoff) {
+		state.restarts = state.restarts[1:]
+	}
+
+	restarts := len(state.restarts)
+	if restarts < w.crashLoopThreshold {
+		if state.looping {
+			slog.Info("Container stopped crash looping", "containerID", c.ID)
+		}
+		state.looping = false
+		w.mu.Unlock()
+		return
+	}
+
+	update := state.looping
+	if update && at.Sub(state.alertedAt) < w.crashLoopUpdateInterval {
+		w.mu.Unlock()
+		return
+	}
+
+	state.looping = true
+	state.alertedAt = at
+	lines := append([]*logfilter.MatchedLine(nil), w.recentErrors[c.ID]...)
+	w.mu.Unlock()
+
+	loop := &CrashLoop{
+		Container: c,
+		Restarts:  restarts,
+		Window:    w.crashLoopWindow,
+		Update:    update,
+		Lines:     lines,
+	}
+
+	if state, err := w.client.ContainerState(ctx, c.ID); err != nil {
+		slog.Error("Failed to inspect crash looping container", "containerID", c.ID, "err", err)
+	} else {
+		loop.RestartCount = state.RestartCount
+	}
+
+	if len(loop.Lines) == 0 {
+		lines, err := w.lastLines(ctx, c.ID, w.crashLogLines)
+		if err != nil {
+			slog.Error("Failed to get last log lines", "containerID", c.ID, "err", err)
+		}
+		loop.Lines = lines
+	}
+
+	select {
+	case w.CrashLoops <- loop:
+	case <-ctx.Done():
+	}
+}
+
+// isLooping reports whether the container is in a crash loop. Individual
+// crash and error alerts of looping containers are folded into the
+// CrashLoop alerts. A container that has not restarted for a whole window
+// is no longer looping.
+func (w *Watcher) isLooping(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state, ok := w.loops[id]
+	if !ok || !state.looping {
+		return false
+	}
+
+	if last := state.restarts[len(state.restarts)-1]; w.now().Sub(last) > w.crashLoopWindow {
+		slog.Info("Container stopped crash looping", "containerID", id)
+		state.looping = false
+		state.restarts = nil
+		return false
+	}
+	return true
+}
+
+func (w *Watcher) rememberError(id string, line *logfilter.MatchedLine) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	recent := append(w.recentErrors[id], line)
+	if len(recent) > maxRecentErrors {
+		recent = recent[len(recent)-maxRecentErrors:]
+	}
+	w.recentErrors[id] = recent
+}
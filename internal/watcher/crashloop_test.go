@@ -0,0 +1,178 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+func TestWatcher_crashLoop(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container", Image: "app:latest"}
+
+	client := NewMockContainerClient()
+	client.SetState(c.ID, &container.State{ExitCode: 1, RestartCount: 7})
+	client.SetLogs(c.ID, []byte("2023-03-15T12:00:00.000000000Z ERROR: cannot connect to database\n"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:                time.Second,
+		ErrorPatterns:           []string{"ERROR"},
+		CrashAlerts:             true,
+		CrashLogLines:           10,
+		CrashLoopThreshold:      3,
+		CrashLoopWindow:         10 * time.Minute,
+		CrashLoopUpdateInterval: 30 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 100)
+	watcher.Crashes = make(chan *Crash, 100)
+	watcher.CrashLoops = make(chan *CrashLoop, 100)
+
+	ctx := context.Background()
+	start := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+	now := start
+	watcher.now = func() time.Time { return now }
+
+	watcher.handleEvent(ctx, container.Event{Action: container.EventStart, Container: c, Time: start})
+
+	restart := func(at time.Time) {
+		now = at
+		watcher.handleEvent(ctx, container.Event{
+			Action:     container.EventDie,
+			Container:  c,
+			Attributes: map[string]string{"exitCode": "1"},
+			Time:       at,
+		})
+		watcher.handleEvent(ctx, container.Event{Action: container.EventStart, Container: c, Time: at})
+	}
+
+	for i := 1; i <= 2; i++ {
+		restart(start.Add(time.Duration(i) * time.Minute))
+	}
+
+	if len(watcher.CrashLoops) != 0 {
+		t.Fatalf("expected no crash loop below the threshold, got %d", len(watcher.CrashLoops))
+	}
+	if len(watcher.Crashes) != 2 {
+		t.Fatalf("expected 2 crash alerts before the loop, got %d", len(watcher.Crashes))
+	}
+
+	restart(start.Add(3 * time.Minute))
+
+	if len(watcher.CrashLoops) != 1 {
+		t.Fatalf("expected a crash loop alert, got %d", len(watcher.CrashLoops))
+	}
+
+	loop := <-watcher.CrashLoops
+	if loop.Update {
+		t.Error("expected the first alert not to be an update")
+	}
+	if loop.Restarts != 3 || loop.RestartCount != 7 || loop.Container.Image != "app:latest" {
+		t.Errorf("unexpected crash loop: %+v", loop)
+	}
+	if len(loop.Lines) != 1 || string(loop.Lines[0].Content) != "ERROR: cannot connect to database" {
+		t.Errorf("expected the last error lines, got %d lines", len(loop.Lines))
+	}
+
+	crashes := len(watcher.Crashes)
+	for i := 4; i <= 32; i++ {
+		restart(start.Add(time.Duration(i) * time.Minute))
+	}
+
+	if len(watcher.CrashLoops) != 0 {
+		t.Errorf("expected no updates within the update interval, got %d", len(watcher.CrashLoops))
+	}
+	if len(watcher.Crashes) != crashes {
+		t.Errorf("expected crash alerts to be folded into the loop, got %d new", len(watcher.Crashes)-crashes)
+	}
+
+	restart(start.Add(33 * time.Minute))
+
+	if len(watcher.CrashLoops) != 1 {
+		t.Fatalf("expected a periodic update, got %d", len(watcher.CrashLoops))
+	}
+	if loop := <-watcher.CrashLoops; !loop.Update {
+		t.Error("expected the periodic alert to be an update")
+	}
+
+	watcher.handleEvent(ctx, container.Event{Action: container.EventDestroy, Container: c})
+
+	if _, ok := watcher.loops[c.ID]; ok {
+		t.Error("expected loop state to be dropped with the container")
+	}
+}
+
+func TestWatcher_crashLoopEnds(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetContainers([]container.Container{c})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:                time.Second,
+		ErrorPatterns:           []string{"ERROR"},
+		CrashAlerts:             true,
+		CrashLoopThreshold:      3,
+		CrashLoopWindow:         10 * time.Minute,
+		CrashLoopUpdateInterval: 30 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 100)
+	watcher.Crashes = make(chan *Crash, 100)
+	watcher.CrashLoops = make(chan *CrashLoop, 100)
+
+	ctx := context.Background()
+	start := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+	now := start
+	watcher.now = func() time.Time { return now }
+
+	watcher.handleEvent(ctx, container.Event{Action: container.EventStart, Container: c, Time: start})
+	for i := 1; i <= 3; i++ {
+		now = start.Add(time.Duration(i) * time.Minute)
+		watcher.handleEvent(ctx, container.Event{
+			Action:     container.EventDie,
+			Container:  c,
+			Attributes: map[string]string{"exitCode": "1"},
+			Time:       now,
+		})
+		watcher.handleEvent(ctx, container.Event{Action: container.EventStart, Container: c, Time: now})
+	}
+	if len(watcher.CrashLoops) != 1 {
+		t.Fatalf("expected a crash loop alert, got %d", len(watcher.CrashLoops))
+	}
+
+	poll := func(logs string) {
+		client.SetLogs(c.ID, []byte(logs))
+		if err := watcher.processContainerLogs(ctx, c); err != nil {
+			t.Fatalf("processContainerLogs failed: %v", err)
+		}
+	}
+
+	// Errors of a looping container are folded into the loop.
+	now = start.Add(4 * time.Minute)
+	poll("2023-03-15T12:04:00.000000000Z ERROR: while looping\n")
+	if len(watcher.C) != 0 {
+		t.Fatalf("expected errors to be folded into the crash loop, got %d alerts", len(watcher.C))
+	}
+
+	// The container stays up for longer than the window.
+	now = start.Add(20 * time.Minute)
+	poll("2023-03-15T12:20:00.000000000Z ERROR: after the loop\n")
+
+	select {
+	case m := <-watcher.C:
+		if string(m.Line.Content) != "ERROR: after the loop" {
+			t.Errorf("unexpected alert: %s", m.Line.Content)
+		}
+	default:
+		t.Fatal("expected the error after the loop to be sent")
+	}
+	if watcher.isLooping(c.ID) {
+		t.Error("expected the container to no longer be looping")
+	}
+}
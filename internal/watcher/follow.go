@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+const followReconnectDelay = time.Second
+
+type stream struct {
+	cancel context.CancelFunc
+}
+
+// startFollowing opens a log stream for the container unless one is already
+// running. The caller must hold w.mu.
+func (w *Watcher) startFollowing(ctx context.Context, id string) {
+	if _, ok := w.streams[id]; ok {
+		return
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	s := &stream{cancel: cancel}
+	w.streams[id] = s
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+		w.followContainer(streamCtx, id, s)
+	}()
+}
+
+// stopFollowing closes the log stream of the container. The caller must hold
+// w.mu.
+func (w *Watcher) stopFollowing(id string) {
+	if s, ok := w.streams[id]; ok {
+		s.cancel()
+		delete(w.streams, id)
+	}
+}
+
+// followContainer keeps a log stream open for as long as the container is
+// watched. When the stream ends, because the container stopped or the
+// connection dropped, it reconnects from the last timestamp seen.
+func (w *Watcher) followContainer(ctx context.Context, id string, s *stream) {
+	for {
+		w.mu.Lock()
+		c, watched := w.containers[id]
+		since := w.offsets[id]
+		if !watched || ctx.Err() != nil {
+			if w.streams[id] == s {
+				delete(w.streams, id)
+			}
+			if !watched {
+				delete(w.offsets, id)
+			}
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		if err := w.followContainerLogs(ctx, c, since); err != nil && ctx.Err() == nil {
+			slog.Error("Log stream failed", "containerID", id, "err", err)
+		}
+
+		select {
+		case <-time.After(followReconnectDelay):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (w *Watcher) followContainerLogs(ctx context.Context, container container.Container, since string) error {
+	sinceTime, err := parseStrSince(since)
+	if err != nil {
+		return fmt.Errorf("Failed to follow logs for container %s: %w", container.ID, err)
+	}
+
+	logs, err := w.client.FollowLogs(ctx, container.ID, since)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	slog.Debug("Following container logs", "containerID", container.ID, "since", since)
+
+	if _, err := w.scanLogs(ctx, containerSource(container), w.newLogScanner(container.ID, logs), sinceTime); err != nil {
+		return fmt.Errorf("Failed to process logs for container %s: %w", container.ID, err)
+	}
+
+	return nil
+}
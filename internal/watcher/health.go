@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+type HealthChange struct {
+	Container container.Container
+	Status    string
+	Previous  string
+	// FailingStreak and Probe describe the failing healthcheck. They are only
+	// set when the container became unhealthy.
+	FailingStreak int
+	Probe         *container.HealthProbe
+}
+
+// Recovered reports whether the container became healthy after being
+// unhealthy.
+func (h *HealthChange) Recovered() bool {
+	return h.Status == container.HealthHealthy
+}
+
+// reportHealth sends a HealthChange when a watched container turns unhealthy
+// and when it becomes healthy again. Other transitions, such as starting to
+// healthy, are only remembered.
+func (w *Watcher) reportHealth(ctx context.Context, c container.Container, status string) {
+	w.mu.Lock()
+	previous := w.health[c.ID]
+	w.health[c.ID] = status
+	w.mu.Unlock()
+
+	if status == previous {
+		return
+	}
+
+	change := &HealthChange{
+		Container: c,
+		Status:    status,
+		Previous:  previous,
+	}
+
+	switch status {
+	case container.HealthUnhealthy:
+		state, err := w.client.ContainerState(ctx, c.ID)
+		if err != nil {
+			slog.Error("Failed to inspect unhealthy container", "containerID", c.ID, "err", err)
+		} else {
+			change.FailingStreak = state.FailingStreak
+			change.Probe = w.redactProbe(state.LastFailedProbe)
+		}
+	case container.HealthHealthy:
+		if previous != container.HealthUnhealthy {
+			return
+		}
+	default:
+		return
+	}
+
+	select {
+	case w.Health <- change:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+func TestWatcher_reportHealth(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetState(c.ID, &container.State{
+		Health:          container.HealthUnhealthy,
+		FailingStreak:   3,
+		LastFailedProbe: &container.HealthProbe{ExitCode: 1, Output: "curl: (7) Failed to connect"},
+	})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Second,
+		ErrorPatterns: []string{"ERROR"},
+		HealthAlerts:  true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.Health = make(chan *HealthChange, 10)
+	watcher.containers[c.ID] = c
+
+	ctx := context.Background()
+	health := func(status string) {
+		watcher.handleEvent(ctx, container.Event{
+			Action:    container.EventHealthStatus,
+			Status:    status,
+			Container: c,
+		})
+	}
+
+	health(container.HealthHealthy)
+	if len(watcher.Health) != 0 {
+		t.Fatalf("expected no alert for the first healthy status, got %d", len(watcher.Health))
+	}
+
+	health(container.HealthUnhealthy)
+	health(container.HealthUnhealthy)
+	if len(watcher.Health) != 1 {
+		t.Fatalf("expected one unhealthy alert, got %d", len(watcher.Health))
+	}
+
+	change := <-watcher.Health
+	if change.Recovered() || change.Previous != container.HealthHealthy {
+		t.Errorf("unexpected change: %+v", change)
+	}
+	if change.FailingStreak != 3 || change.Probe == nil || change.Probe.Output != "curl: (7) Failed to connect" {
+		t.Errorf("expected the failing probe in the alert, got %+v", change)
+	}
+
+	health(container.HealthHealthy)
+	if len(watcher.Health) != 1 {
+		t.Fatalf("expected a recovery alert, got %d", len(watcher.Health))
+	}
+
+	if change := <-watcher.Health; !change.Recovered() || change.Probe != nil {
+		t.Errorf("unexpected recovery: %+v", change)
+	}
+}
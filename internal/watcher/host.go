@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type HostStatus struct {
+	// Host is the endpoint name given in WatcherOptions.
+	Host string
+	// Lost is set when the daemon stopped answering, and cleared when it is
+	// reachable again.
+	Lost bool
+	// Since is when the daemon first failed to answer.
+	Since time.Time
+	Err   error
+}
+
+// Recovered reports whether the daemon is reachable again after being lost.
+func (h *HostStatus) Recovered() bool {
+	return !h.Lost
+}
+
+// hostHealth counts the checks and polls of the daemon that failed in a row.
+type hostHealth struct {
+	failures int
+	since    time.Time
+}
+
+// hostFailed counts a failed check or poll, and reports the host lost once
+// maxConsecutiveFailures of them failed in a row.
+func (w *Watcher) hostFailed(ctx context.Context, h *hostHealth, err error) {
+	if h.failures == 0 {
+		h.since = time.Now()
+	}
+	h.failures++
+
+	if h.failures == maxConsecutiveFailures {
+		slog.Error("Too many consecutive failures, Docker host is lost", "host", w.host)
+		w.reportHost(ctx, true, h.since, err)
+	}
+}
+
+// hostAnswered resets the count of failures, and reports the host reachable
+// again if it was lost.
+func (w *Watcher) hostAnswered(ctx context.Context, h *hostHealth) {
+	if h.failures >= maxConsecutiveFailures {
+		slog.Info("Docker host is reachable again", "host", w.host)
+		w.reportHost(ctx, false, h.since, nil)
+	}
+	h.failures = 0
+}
+
+// reportHost sends a HostStatus when the daemon is lost and when it comes
+// back.
+func (w *Watcher) reportHost(ctx context.Context, lost bool, since time.Time, err error) {
+	if !w.hostAlerts {
+		return
+	}
+
+	status := &HostStatus{
+		Host:  w.host,
+		Lost:  lost,
+		Since: since,
+		Err:   err,
+	}
+
+	select {
+	case w.Hosts <- status:
+	case <-ctx.Done():
+	}
+}
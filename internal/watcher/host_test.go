@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+)
+
+func TestWatcher_hostLostAndRecovered(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetContainersError(errors.New("connection refused"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+		Host:          "docker-host-1",
+		HostAlerts:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.Hosts = make(chan *HostStatus, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.start(ctx)
+	}()
+
+	var lost *HostStatus
+	select {
+	case lost = <-watcher.Hosts:
+	case <-time.After(time.Second):
+		t.Fatal("expected a lost host alert")
+	}
+	if !lost.Lost || lost.Host != "docker-host-1" || lost.Err == nil || lost.Since.IsZero() {
+		t.Errorf("unexpected status: %+v", lost)
+	}
+
+	client.SetContainersError(nil)
+
+	var recovered *HostStatus
+	select {
+	case recovered = <-watcher.Hosts:
+	case <-time.After(time.Second):
+		t.Fatal("expected a recovered host alert")
+	}
+	if !recovered.Recovered() || !recovered.Since.Equal(lost.Since) {
+		t.Errorf("unexpected status: %+v", recovered)
+	}
+
+	cancel()
+	<-done
+
+	if len(watcher.Hosts) != 0 {
+		t.Errorf("expected no more host alerts, got %d", len(watcher.Hosts))
+	}
+}
+
+func TestWatcher_hostLostOnPollFailures(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetContainers([]container.Container{
+		{ID: "container1", Name: "web"},
+		{ID: "container2", Name: "db"},
+	})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+		Host:          "docker-host-1",
+		HostAlerts:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.Hosts = make(chan *HostStatus, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.start(ctx)
+	}()
+
+	// Wait for the first listing, after which only logs are polled.
+	deadline := time.After(time.Second)
+	for client.ContainersCallCount() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected the containers to be listed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	client.SetLogsError(errors.New("connection refused"))
+
+	var lost *HostStatus
+	select {
+	case lost = <-watcher.Hosts:
+	case <-time.After(time.Second):
+		t.Fatal("expected a lost host alert")
+	}
+	if !lost.Lost || lost.Host != "docker-host-1" || lost.Err == nil {
+		t.Errorf("unexpected status: %+v", lost)
+	}
+	if calls := client.ContainersCallCount(); calls != 1 {
+		t.Errorf("expected the host to be lost on log polls alone, got %d listings", calls)
+	}
+
+	client.SetLogsError(nil)
+
+	select {
+	case recovered := <-watcher.Hosts:
+		if !recovered.Recovered() {
+			t.Errorf("unexpected status: %+v", recovered)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a recovered host alert")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestWatcher_processContainers_partialFailure(t *testing.T) {
+	client := &failingLogsClient{MockContainerClient: NewMockContainerClient(), failing: "container2"}
+	client.SetContainers([]container.Container{
+		{ID: "container1", Name: "web"},
+		{ID: "container2", Name: "db"},
+	})
+
+	watcher, err := New(client, &WatcherOptions{Interval: time.Second, ErrorPatterns: []string{"ERROR"}})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := watcher.syncContainers(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// One container whose logs cannot be read does not make the host lost.
+	if err := watcher.processContainers(ctx); err != nil {
+		t.Errorf("expected no error when some logs were read, got %v", err)
+	}
+
+	client.failing = ""
+	client.SetLogsError(errors.New("connection refused"))
+	if err := watcher.processContainers(ctx); err == nil {
+		t.Error("expected an error when no logs could be read")
+	}
+}
+
+// failingLogsClient fails to return the logs of one container.
+type failingLogsClient struct {
+	*MockContainerClient
+	failing string
+}
+
+func (c *failingLogsClient) ContainerLogs(ctx context.Context, id, since string, tail int) (*docker.LogStream, error) {
+	if id == c.failing {
+		return nil, errors.New("no such container")
+	}
+	return c.MockContainerClient.ContainerLogs(ctx, id, since, tail)
+}
@@ -1,10 +1,14 @@
 package watcher
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"sync"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
 )
 
 // MockContainerClient is a mock implementation of ContainerClient for testing
@@ -12,17 +16,34 @@ type MockContainerClient struct {
 	mu                  sync.Mutex
 	containers          []container.Container
 	logs                map[string][]byte
+	multiplexed         map[string]bool
 	containersErr       error
 	logsErr             error
+	states              map[string]*container.State
+	events              chan container.Event
+	eventsErr           error
 	closeCallCount      int
 	containersCallCount int
 	logsCallCount       int
+	eventsCallCount     int
+	followCallCount     int
+	services            []container.Service
+	servicesErr         error
+	tasks               map[string][]container.Task
+	serviceLogs         map[string][]byte
+	tasksCallCount      int
 }
 
 // NewMockContainerClient creates a new MockContainerClient
 func NewMockContainerClient() *MockContainerClient {
 	return &MockContainerClient{
-		logs: make(map[string][]byte),
+		logs:        make(map[string][]byte),
+		multiplexed: make(map[string]bool),
+		states:      make(map[string]*container.State),
+		events:      make(chan container.Event, 16),
+
+		tasks:       make(map[string][]container.Task),
+		serviceLogs: make(map[string][]byte),
 	}
 }
 
@@ -45,6 +66,16 @@ func (m *MockContainerClient) SetLogs(id string, logs []byte) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.logs[id] = logs
+	m.multiplexed[id] = false
+}
+
+// SetMultiplexedLogs is SetLogs for logs framed as for a container without a
+// TTY
+func (m *MockContainerClient) SetMultiplexedLogs(id string, logs []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logs[id] = logs
+	m.multiplexed[id] = true
 }
 
 // SetLogsError sets the error to be returned by ContainerLogs
@@ -54,6 +85,54 @@ func (m *MockContainerClient) SetLogsError(err error) {
 	m.logsErr = err
 }
 
+// SetState sets the state to be returned by ContainerState for a specific container
+func (m *MockContainerClient) SetState(id string, state *container.State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[id] = state
+}
+
+// SetEventsError sets the error to be returned by ContainerEvents
+func (m *MockContainerClient) SetEventsError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventsErr = err
+}
+
+// SendEvent queues an event to be delivered to ContainerEvents subscribers
+func (m *MockContainerClient) SendEvent(event container.Event) {
+	m.events <- event
+}
+
+// SetServices sets the services to be returned by Services
+func (m *MockContainerClient) SetServices(services []container.Service) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.services = services
+}
+
+// SetServicesError sets the error to be returned by Services
+func (m *MockContainerClient) SetServicesError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servicesErr = err
+}
+
+// SetTasks sets the tasks to be returned by ServiceTasks for a specific service
+func (m *MockContainerClient) SetTasks(serviceID string, tasks []container.Task) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[serviceID] = tasks
+}
+
+// SetServiceLogs sets the logs to be returned by FollowServiceLogs for a
+// specific service. Service logs are framed.
+func (m *MockContainerClient) SetServiceLogs(serviceID string, logs []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.serviceLogs[serviceID] = logs
+}
+
 // RunningContainers implements ContainerClient.RunningContainers
 func (m *MockContainerClient) RunningContainers(ctx context.Context) ([]container.Container, error) {
 	m.mu.Lock()
@@ -66,18 +145,98 @@ func (m *MockContainerClient) RunningContainers(ctx context.Context) ([]containe
 }
 
 // ContainerLogs implements ContainerClient.ContainerLogs
-func (m *MockContainerClient) ContainerLogs(ctx context.Context, id, since string, tail int) ([]byte, error) {
+func (m *MockContainerClient) ContainerLogs(ctx context.Context, id, since string, tail int) (*docker.LogStream, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.logsCallCount++
 	if m.logsErr != nil {
 		return nil, m.logsErr
 	}
-	logs, ok := m.logs[id]
+	return &docker.LogStream{
+		ReadCloser:  io.NopCloser(bytes.NewReader(m.logs[id])),
+		Multiplexed: m.multiplexed[id],
+	}, nil
+}
+
+// ContainerInspect implements ContainerClient.ContainerInspect. It returns
+// the container with the given ID from the list set with SetContainers.
+func (m *MockContainerClient) ContainerInspect(ctx context.Context, id string) (*container.Container, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.containers {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s not found", id)
+}
+
+// ContainerState implements ContainerClient.ContainerState
+func (m *MockContainerClient) ContainerState(ctx context.Context, id string) (*container.State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[id]
 	if !ok {
-		return []byte{}, nil
+		return &container.State{}, nil
+	}
+	return state, nil
+}
+
+// FollowLogs implements ContainerClient.FollowLogs. The returned stream
+// contains the logs set with SetLogs and ends right after them.
+func (m *MockContainerClient) FollowLogs(ctx context.Context, id, since string) (*docker.LogStream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.followCallCount++
+	if m.logsErr != nil {
+		return nil, m.logsErr
+	}
+	return &docker.LogStream{
+		ReadCloser:  io.NopCloser(bytes.NewReader(m.logs[id])),
+		Multiplexed: m.multiplexed[id],
+	}, nil
+}
+
+// ContainerEvents implements ContainerClient.ContainerEvents
+func (m *MockContainerClient) ContainerEvents(ctx context.Context) (<-chan container.Event, <-chan error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventsCallCount++
+	errs := make(chan error, 1)
+	if m.eventsErr != nil {
+		errs <- m.eventsErr
+	}
+	return m.events, errs
+}
+
+// Services implements ContainerClient.Services
+func (m *MockContainerClient) Services(ctx context.Context) ([]container.Service, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.servicesErr != nil {
+		return nil, m.servicesErr
 	}
-	return logs, nil
+	return m.services, nil
+}
+
+// ServiceTasks implements ContainerClient.ServiceTasks
+func (m *MockContainerClient) ServiceTasks(ctx context.Context, serviceID string) ([]container.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasksCallCount++
+	return m.tasks[serviceID], nil
+}
+
+// FollowServiceLogs implements ContainerClient.FollowServiceLogs. The
+// returned stream contains the logs set with SetServiceLogs and ends right
+// after them.
+func (m *MockContainerClient) FollowServiceLogs(ctx context.Context, serviceID, since string) (*docker.LogStream, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &docker.LogStream{
+		ReadCloser:  io.NopCloser(bytes.NewReader(m.serviceLogs[serviceID])),
+		Multiplexed: true,
+	}, nil
 }
 
 // Close implements ContainerClient.Close
@@ -102,6 +261,27 @@ func (m *MockContainerClient) LogsCallCount() int {
 	return m.logsCallCount
 }
 
+// EventsCallCount returns the number of calls to ContainerEvents
+func (m *MockContainerClient) EventsCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.eventsCallCount
+}
+
+// FollowCallCount returns the number of calls to FollowLogs
+func (m *MockContainerClient) FollowCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.followCallCount
+}
+
+// TasksCallCount returns the number of calls to ServiceTasks
+func (m *MockContainerClient) TasksCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tasksCallCount
+}
+
 // CloseCallCount returns the number of calls to Close
 func (m *MockContainerClient) CloseCallCount() int {
 	m.mu.Lock()
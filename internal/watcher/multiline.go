@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// idleFlushTimeout is how long a multi-line event waits for more
+// continuation lines, and a match for its after context, on an idle stream.
+const idleFlushTimeout = time.Second
+
+// scanEvents is scanLogs for multi-line events and matches with context.
+// Lines are read in a separate goroutine, so that what is pending is sent
+// once a followed stream is idle instead of when the next line arrives.
+func (w *Watcher) scanEvents(ctx context.Context, scanner *logScanner, r *logReader) (time.Time, error) {
+	scanCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type streamLine struct {
+		stream logfilter.Stream
+		data   []byte
+	}
+
+	lines := make(chan streamLine)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			select {
+			case lines <- streamLine{scanner.logStream(), bytes.Clone(scanner.Line())}:
+			case <-scanCtx.Done():
+				return
+			}
+		}
+		errs <- scanner.Err()
+	}()
+
+	flush := time.NewTimer(idleFlushTimeout)
+	flush.Stop()
+	defer flush.Stop()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if err := r.flush(ctx); err != nil {
+					return r.last, err
+				}
+				return r.last, <-errs
+			}
+
+			matches, event, ok := r.read(ctx, line.stream, line.data)
+			if !ok {
+				continue
+			}
+
+			if err := matches.feed(ctx, event); err != nil {
+				return r.last, err
+			}
+			flush.Reset(idleFlushTimeout)
+		case <-flush.C:
+			if err := r.flush(ctx); err != nil {
+				return r.last, err
+			}
+		case <-ctx.Done():
+			return r.last, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func newMultilineWatcher(t *testing.T, client ContainerClient, offsets map[string]string) *Watcher {
+	t.Helper()
+
+	matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: []string{"exception"}})
+	if err != nil {
+		t.Fatalf("failed to compile patterns: %v", err)
+	}
+
+	multiline, err := logfilter.NewMultilineOptions("java", "", "", 0)
+	if err != nil {
+		t.Fatalf("failed to create multiline options: %v", err)
+	}
+
+	return &Watcher{
+		client:    client,
+		matcher:   matcher,
+		multiline: multiline,
+		offsets:   offsets,
+		C:         make(chan *MatchedLog, 10),
+	}
+}
+
+func TestWatcher_processContainerLogs_multiline(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetLogs(c.ID, []byte(
+		"2023-03-15T12:01:00.000000000Z java.lang.IllegalStateException: boom\n"+
+			"2023-03-15T12:01:00.000000001Z \tat com.example.App.run(App.java:10)\n"+
+			"2023-03-15T12:01:00.000000002Z \tat com.example.Exception.main(App.java:5)\n"+
+			"2023-03-15T12:02:00.000000000Z INFO recovered\n"+
+			"2023-03-15T12:03:00.000000000Z java.lang.RuntimeException: again\n"+
+			"2023-03-15T12:03:00.000000001Z \tat com.example.App.run(App.java:10)",
+	))
+
+	watcher := newMultilineWatcher(t, client, map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"})
+
+	if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+		t.Fatalf("processContainerLogs failed: %v", err)
+	}
+	close(watcher.C)
+
+	var got []*logfilter.MatchedLine
+	for match := range watcher.C {
+		got = append(got, match.Line)
+	}
+
+	want := []string{
+		"java.lang.IllegalStateException: boom\n\tat com.example.App.run(App.java:10)\n\tat com.example.Exception.main(App.java:5)",
+		"java.lang.RuntimeException: again\n\tat com.example.App.run(App.java:10)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if string(got[i].Content) != want[i] {
+			t.Errorf("event %d: expected %q, got %q", i, want[i], got[i].Content)
+		}
+	}
+
+	if ts := string(got[0].Timestamp); ts != "2023-03-15T12:01:00.000000000Z" {
+		t.Errorf("expected the timestamp of the first line, got %s", ts)
+	}
+
+	if offset := watcher.offsets[c.ID]; offset != "2023-03-15T12:03:00.000000001Z" {
+		t.Errorf("expected offset of the last line, got %s", offset)
+	}
+}
+
+func TestWatcher_scanLogs_multilineIdleFlush(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+	watcher := newMultilineWatcher(t, NewMockContainerClient(), map[string]string{})
+
+	r, w := io.Pipe()
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watcher.scanLogs(ctx, containerSource(c), watcher.newLogScanner(c.ID, &docker.LogStream{ReadCloser: r}), time.Time{})
+	}()
+
+	// The stream stays open, so the trace is only complete once it is idle.
+	io.WriteString(w, "2023-03-15T12:01:00.000000000Z java.lang.IllegalStateException: boom\n")
+	io.WriteString(w, "2023-03-15T12:01:00.000000001Z \tat com.example.App.run(App.java:10)\n")
+
+	select {
+	case match := <-watcher.C:
+		want := "java.lang.IllegalStateException: boom\n\tat com.example.App.run(App.java:10)"
+		if string(match.Line.Content) != want {
+			t.Errorf("expected %q, got %q", want, match.Line.Content)
+		}
+	case <-time.After(idleFlushTimeout + time.Second):
+		t.Fatal("expected the pending event to be flushed on an idle stream")
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,36 @@
+package watcher
+
+import (
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// redactLine masks secrets in the content and fields of a line the watcher
+// owns.
+func (w *Watcher) redactLine(line *logfilter.MatchedLine) {
+	if w.redactor == nil {
+		return
+	}
+
+	line.Content = w.redactor.Redact(line.Content)
+	if len(line.Fields) == 0 {
+		return
+	}
+
+	fields := make([]logfilter.Field, len(line.Fields))
+	for i, field := range line.Fields {
+		fields[i] = logfilter.Field{Key: field.Key, Value: w.redactor.RedactString(field.Value)}
+	}
+	line.Fields = fields
+}
+
+// redactProbe returns a copy of the probe with secrets in its output masked.
+func (w *Watcher) redactProbe(probe *container.HealthProbe) *container.HealthProbe {
+	if probe == nil || w.redactor == nil {
+		return probe
+	}
+
+	redacted := *probe
+	redacted.Output = w.redactor.RedactString(probe.Output)
+	return &redacted
+}
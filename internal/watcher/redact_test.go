@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/redact"
+)
+
+func TestWatcher_processContainerLogs_redact(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetLogs(c.ID, []byte(strings.Join([]string{
+		"2023-03-15T12:00:01.000000000Z connecting to postgres://app:Pa55word@db/orders",
+		`2023-03-15T12:00:02.000000000Z {"level":"error","msg":"login failed password=hunter2","user":"jane@example.com"}`,
+	}, "\n")))
+
+	opts := &WatcherOptions{
+		Rules:       []logfilter.RuleConfig{{Name: "login", Patterns: []string{"password"}, ContextBefore: 1}},
+		LogFormat:   "json",
+		AlertFields: []string{"msg", "user"},
+	}
+	matcher, err := newMatcher(opts)
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+	redactor, err := redact.New(redact.Detectors(), nil)
+	if err != nil {
+		t.Fatalf("failed to create redactor: %v", err)
+	}
+
+	watcher := &Watcher{
+		client:   client,
+		matcher:  matcher,
+		redactor: redactor,
+		offsets:  map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+		C:        make(chan *MatchedLog, 10),
+	}
+
+	if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+		t.Fatalf("processContainerLogs failed: %v", err)
+	}
+	close(watcher.C)
+
+	match, ok := <-watcher.C
+	if !ok {
+		t.Fatal("expected the line to match before it is redacted")
+	}
+
+	want := `{"level":"error","msg":"login failed password=[REDACTED:password]","user":"[REDACTED:email]"}`
+	if got := string(match.Line.Content); got != want {
+		t.Errorf("expected content %q, got %q", want, got)
+	}
+
+	wantFields := []logfilter.Field{{Key: "msg", Value: "login failed password=[REDACTED:password]"}, {Key: "user", Value: "[REDACTED:email]"}}
+	if len(match.Line.Fields) != len(wantFields) {
+		t.Fatalf("expected fields %v, got %v", wantFields, match.Line.Fields)
+	}
+	for i := range wantFields {
+		if match.Line.Fields[i] != wantFields[i] {
+			t.Errorf("field %d: expected %v, got %v", i, wantFields[i], match.Line.Fields[i])
+		}
+	}
+
+	if len(match.Context) != 2 {
+		t.Fatalf("expected 2 context lines, got %d", len(match.Context))
+	}
+	if got := string(match.Context[0].Line.Content); got != "connecting to postgres://app:[REDACTED:url-credentials]@db/orders" {
+		t.Errorf("expected the context line to be redacted, got %q", got)
+	}
+}
+
+func TestWatcher_processContainerLogs_redactOnce(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetLogs(c.ID, []byte(strings.Join([]string{
+		"2023-03-15T12:00:01.000000000Z login failed password=hunter2 (1)",
+		"2023-03-15T12:00:02.000000000Z login failed password=hunter2 (2)",
+	}, "\n")))
+
+	opts := &WatcherOptions{
+		Rules: []logfilter.RuleConfig{
+			{Name: "login", Patterns: []string{"login failed"}, Threshold: 2, Window: "1m", ContextBefore: 1},
+		},
+	}
+	matcher, err := newMatcher(opts)
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+	// The pattern also matches its own mask, so a line redacted twice shows
+	// a nested mask.
+	redactor, err := redact.New(nil, []string{"hunter2|REDACTED"})
+	if err != nil {
+		t.Fatalf("failed to create redactor: %v", err)
+	}
+
+	watcher := &Watcher{
+		client:   client,
+		matcher:  matcher,
+		redactor: redactor,
+		offsets:  map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+		C:        make(chan *MatchedLog, 10),
+	}
+
+	if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+		t.Fatalf("processContainerLogs failed: %v", err)
+	}
+	close(watcher.C)
+
+	match, ok := <-watcher.C
+	if !ok {
+		t.Fatal("expected the threshold to be crossed")
+	}
+
+	if got := string(match.Line.Content); got != "login failed password=[REDACTED:custom] (2)" {
+		t.Errorf("expected the match to be redacted once, got %q", got)
+	}
+	if len(match.Context) != 2 {
+		t.Fatalf("expected 2 context lines, got %d", len(match.Context))
+	}
+	if got := string(match.Context[0].Line.Content); got != "login failed password=[REDACTED:custom] (1)" {
+		t.Errorf("expected the earlier match in the context to be redacted once, got %q", got)
+	}
+	if len(match.Samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(match.Samples))
+	}
+	if got := string(match.Samples[0].Content); got != "login failed password=[REDACTED:custom] (1)" {
+		t.Errorf("expected the sample to be redacted once, got %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package watcher
+
+import (
+	"log/slog"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// logScanner is a docker.LogScanner that cuts long lines and, for polled
+// logs, stops once the poll has read maxLines lines or maxBytes bytes.
+type logScanner struct {
+	*docker.LogScanner
+	id string
+
+	maxLines int
+	maxBytes int64
+	// lines and bytes count what was read within the limits.
+	lines int
+	bytes int64
+	// limited is set when the scanner stopped at a limit before the end of
+	// the stream.
+	limited bool
+}
+
+// newLogScanner returns a scanner for the logs of the container or service
+// with the given ID.
+func (w *Watcher) newLogScanner(id string, logs *docker.LogStream) *logScanner {
+	scanner := docker.NewLogScanner(logs, logs.Multiplexed)
+	scanner.SetMaxLineLength(w.maxLineLength)
+	return &logScanner{LogScanner: scanner, id: id}
+}
+
+// newPollScanner is newLogScanner with the limits of one poll.
+func (w *Watcher) newPollScanner(id string, logs *docker.LogStream) *logScanner {
+	scanner := w.newLogScanner(id, logs)
+	scanner.maxLines = w.pollMaxLines
+	scanner.maxBytes = w.pollMaxBytes
+	return scanner
+}
+
+func (s *logScanner) Scan() bool {
+	if s.limited || !s.LogScanner.Scan() {
+		return false
+	}
+
+	size := int64(len(s.Line()))
+	if (s.maxLines > 0 && s.lines >= s.maxLines) || (s.maxBytes > 0 && s.bytes+size > s.maxBytes) {
+		s.limited = true
+		return false
+	}
+	s.lines++
+	s.bytes += size
+
+	if s.Truncated() {
+		slog.Debug("Truncated long log line", "id", s.id, "maxLength", len(s.Line()))
+	}
+	return true
+}
+
+// logStream returns the stream the most recent line was written to, zero for
+// streams rules cannot select.
+func (s *logScanner) logStream() logfilter.Stream {
+	return logStream(s.Stream())
+}
+
+// logStream maps the stream of a frame to the stream rules select.
+func logStream(stream docker.StreamType) logfilter.Stream {
+	switch stream {
+	case docker.StreamStdout:
+		return logfilter.StreamStdout
+	case docker.StreamStderr:
+		return logfilter.StreamStderr
+	case docker.StreamTTY:
+		return logfilter.StreamTTY
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,227 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestLogStream(t *testing.T) {
+	tests := []struct {
+		stream docker.StreamType
+		want   logfilter.Stream
+	}{
+		{docker.StreamStdout, logfilter.StreamStdout},
+		{docker.StreamStderr, logfilter.StreamStderr},
+		{docker.StreamTTY, logfilter.StreamTTY},
+		{docker.StreamStdin, 0},
+		{docker.StreamSystemErr, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stream.String(), func(t *testing.T) {
+			if got := logStream(tt.stream); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWatcher_processContainerLogs_pollLimits(t *testing.T) {
+	logs := strings.Join([]string{
+		"2023-03-15T12:01:00.000000000Z ERROR: first",
+		"2023-03-15T12:02:00.000000000Z ERROR: second",
+		"2023-03-15T12:03:00.000000000Z ERROR: third",
+	}, "\n")
+
+	tests := []struct {
+		name          string
+		maxLines      int
+		maxBytes      int64
+		want          []string
+		wantSkipped   bool
+		wantOffsetEnd string
+	}{
+		{
+			name:          "within the limits",
+			maxLines:      3,
+			maxBytes:      1024,
+			want:          []string{"ERROR: first", "ERROR: second", "ERROR: third"},
+			wantOffsetEnd: "2023-03-15T12:03:00.000000000Z",
+		},
+		{
+			name:        "line limit",
+			maxLines:    1,
+			want:        []string{"ERROR: first"},
+			wantSkipped: true,
+		},
+		{
+			name:        "byte limit",
+			maxBytes:    100,
+			want:        []string{"ERROR: first", "ERROR: second"},
+			wantSkipped: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := container.Container{ID: "container1", Name: "test-container"}
+
+			client := NewMockContainerClient()
+			client.SetLogs(c.ID, []byte(logs))
+
+			matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: []string{"ERROR"}})
+			if err != nil {
+				t.Fatalf("failed to compile patterns: %v", err)
+			}
+
+			watcher := &Watcher{
+				client:       client,
+				matcher:      matcher,
+				pollMaxLines: tt.maxLines,
+				pollMaxBytes: tt.maxBytes,
+				offsets:      map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+				C:            make(chan *MatchedLog, 10),
+			}
+
+			before := time.Now()
+			if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+				t.Fatalf("processContainerLogs failed: %v", err)
+			}
+			close(watcher.C)
+
+			var got []string
+			for match := range watcher.C {
+				got = append(got, string(match.Line.Content))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+
+			offset, err := parseStrSince(watcher.offsets[c.ID])
+			if err != nil {
+				t.Fatalf("invalid offset: %v", err)
+			}
+			if tt.wantSkipped {
+				if offset.Before(before) {
+					t.Errorf("expected the rest of the output to be skipped, offset is %s", offset)
+				}
+			} else if watcher.offsets[c.ID] != tt.wantOffsetEnd {
+				t.Errorf("expected offset %s, got %s", tt.wantOffsetEnd, watcher.offsets[c.ID])
+			}
+		})
+	}
+}
+
+// generatedLogs produces size bytes of log lines without holding them in
+// memory, and samples the heap while they are read.
+type generatedLogs struct {
+	size     int
+	read     int
+	n        int
+	line     []byte
+	sampled  int
+	peakHeap uint64
+}
+
+func (g *generatedLogs) Read(p []byte) (int, error) {
+	if g.read >= g.size {
+		return 0, io.EOF
+	}
+
+	if g.read-g.sampled >= 256<<10 {
+		g.sampled = g.read
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		g.peakHeap = max(g.peakHeap, stats.HeapAlloc)
+	}
+
+	total := 0
+	for len(p) > 0 && g.read < g.size {
+		if len(g.line) == 0 {
+			g.n++
+			level := "INFO"
+			if g.n%1000 == 0 {
+				level = "ERROR"
+			}
+			g.line = fmt.Appendf(nil, "2023-03-15T12:01:00.000000000Z %s request %d handled in 12ms\n", level, g.n)
+		}
+
+		n := copy(p, g.line)
+		g.line = g.line[n:]
+		p = p[n:]
+		g.read += n
+		total += n
+	}
+	return total, nil
+}
+
+// generatedLogsClient serves generated logs from ContainerLogs.
+type generatedLogsClient struct {
+	*MockContainerClient
+	logs *generatedLogs
+}
+
+func (c *generatedLogsClient) ContainerLogs(ctx context.Context, id, since string, tail int) (*docker.LogStream, error) {
+	return &docker.LogStream{ReadCloser: io.NopCloser(c.logs)}, nil
+}
+
+// BenchmarkWatcher_processContainerLogs polls ever larger log outputs. The
+// peak-heap-bytes metric stays flat, as logs are scanned line by line.
+func BenchmarkWatcher_processContainerLogs(b *testing.B) {
+	for _, size := range []int{1 << 20, 16 << 20, 128 << 20} {
+		b.Run(fmt.Sprintf("%dMiB", size>>20), func(b *testing.B) {
+			c := container.Container{ID: "container1", Name: "test-container"}
+
+			matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: []string{"ERROR"}})
+			if err != nil {
+				b.Fatalf("failed to compile patterns: %v", err)
+			}
+
+			var peakHeap uint64
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				runtime.GC()
+				logs := &generatedLogs{size: size}
+				watcher := &Watcher{
+					client:        &generatedLogsClient{MockContainerClient: NewMockContainerClient(), logs: logs},
+					matcher:       matcher,
+					maxLineLength: 64 << 10,
+					offsets:       map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+					C:             make(chan *MatchedLog),
+				}
+
+				done := make(chan struct{})
+				go func() {
+					for range watcher.C {
+					}
+					close(done)
+				}()
+				b.StartTimer()
+
+				if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+					b.Fatalf("processContainerLogs failed: %v", err)
+				}
+
+				b.StopTimer()
+				close(watcher.C)
+				<-done
+				peakHeap = max(peakHeap, logs.peakHeap)
+				b.StartTimer()
+			}
+
+			b.ReportMetric(float64(peakHeap), "peak-heap-bytes")
+		})
+	}
+}
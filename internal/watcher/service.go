@@ -0,0 +1,185 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+)
+
+// syncServices replaces the known Swarm services with a fresh listing and
+// keeps one log stream open per service.
+func (w *Watcher) syncServices(ctx context.Context) error {
+	services, err := w.client.Services(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to list services: %w", err)
+	}
+
+	listed := make(map[string]container.Service, len(services))
+	for _, service := range services {
+		listed[service.ID] = service
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id := range w.services {
+		if _, ok := listed[id]; !ok {
+			delete(w.serviceOffsets, id)
+			delete(w.counts, id)
+			w.stopFollowingService(id)
+			for taskID, task := range w.tasks {
+				if task.ServiceID == id {
+					delete(w.tasks, taskID)
+				}
+			}
+		}
+	}
+
+	for id := range listed {
+		if _, ok := w.serviceOffsets[id]; !ok {
+			w.serviceOffsets[id] = nowStrSince()
+		}
+	}
+
+	w.services = listed
+
+	for id := range listed {
+		w.startFollowingService(ctx, id)
+	}
+
+	return nil
+}
+
+// startFollowingService opens a log stream for the service unless one is
+// already running. The caller must hold w.mu.
+func (w *Watcher) startFollowingService(ctx context.Context, id string) {
+	if _, ok := w.serviceStreams[id]; ok {
+		return
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	s := &stream{cancel: cancel}
+	w.serviceStreams[id] = s
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+		w.followService(streamCtx, id, s)
+	}()
+}
+
+// stopFollowingService closes the log stream of the service. The caller must
+// hold w.mu.
+func (w *Watcher) stopFollowingService(id string) {
+	if s, ok := w.serviceStreams[id]; ok {
+		s.cancel()
+		delete(w.serviceStreams, id)
+	}
+}
+
+// followService keeps a log stream open for as long as the service exists,
+// reconnecting from the last timestamp seen.
+func (w *Watcher) followService(ctx context.Context, id string, s *stream) {
+	for {
+		w.mu.Lock()
+		service, watched := w.services[id]
+		since := w.serviceOffsets[id]
+		if !watched || ctx.Err() != nil {
+			if w.serviceStreams[id] == s {
+				delete(w.serviceStreams, id)
+			}
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+
+		if err := w.followServiceLogs(ctx, service, since); err != nil && ctx.Err() == nil {
+			slog.Error("Service log stream failed", "serviceID", id, "err", err)
+		}
+
+		select {
+		case <-time.After(followReconnectDelay):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (w *Watcher) followServiceLogs(ctx context.Context, service container.Service, since string) error {
+	sinceTime, err := parseStrSince(since)
+	if err != nil {
+		return fmt.Errorf("Failed to follow logs for service %s: %w", service.ID, err)
+	}
+
+	logs, err := w.client.FollowServiceLogs(ctx, service.ID, since)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	slog.Debug("Following service logs", "serviceID", service.ID, "service", service.Name, "since", since)
+
+	if _, err := w.scanLogs(ctx, serviceSource(service), w.newLogScanner(service.ID, logs), sinceTime); err != nil {
+		return fmt.Errorf("Failed to process logs for service %s: %w", service.ID, err)
+	}
+
+	return nil
+}
+
+// serviceTask looks up the task a service log line came from. Tasks are
+// listed again when an unknown one shows up, for example after a service
+// update replaced them.
+func (w *Watcher) serviceTask(ctx context.Context, serviceID, taskID string) container.Task {
+	unknown := container.Task{ID: taskID, ServiceID: serviceID}
+	if taskID == "" {
+		return unknown
+	}
+
+	w.mu.RLock()
+	task, ok := w.tasks[taskID]
+	w.mu.RUnlock()
+	if ok {
+		return task
+	}
+
+	tasks, err := w.client.ServiceTasks(ctx, serviceID)
+	if err != nil {
+		slog.Error("Failed to list service tasks", "serviceID", serviceID, "err", err)
+		return unknown
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, task := range w.tasks {
+		if task.ServiceID == serviceID {
+			delete(w.tasks, id)
+		}
+	}
+	for _, task := range tasks {
+		w.tasks[task.ID] = task
+	}
+
+	// Remember tasks that are gone already, so that they are not looked up
+	// for every line.
+	if _, ok := w.tasks[taskID]; !ok {
+		w.tasks[taskID] = unknown
+	}
+
+	return w.tasks[taskID]
+}
+
+func (w *Watcher) setServiceOffset(id, offset string) {
+	w.mu.Lock()
+	w.serviceOffsets[id] = offset
+	w.mu.Unlock()
+}
+
+// isServiceTask reports whether the container runs a task of a Swarm service
+// whose logs are watched through the service instead.
+func (w *Watcher) isServiceTask(c container.Container) bool {
+	return w.swarmServices && c.Labels[container.LabelSwarmServiceID] != ""
+}
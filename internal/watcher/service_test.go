@@ -0,0 +1,261 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+)
+
+func TestWatcher_scanLogs_service(t *testing.T) {
+	service := container.Service{ID: "service1", Name: "shop_web", Image: "shop/web:1.2", Host: "manager-1"}
+
+	client := NewMockContainerClient()
+	client.SetTasks(service.ID, []container.Task{
+		{ID: "task1", ServiceID: service.ID, NodeID: "node1", Slot: 1},
+		{ID: "task2", ServiceID: service.ID, NodeID: "node2", Slot: 2},
+	})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Second,
+		ErrorPatterns: []string{"ERROR"},
+		SwarmServices: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 10)
+
+	details := func(task string) string {
+		return "com.docker.swarm.node.id=node1,com.docker.swarm.service.id=service1,com.docker.swarm.task.id=" + task
+	}
+
+	var logs []byte
+	logs = append(logs, frame(1, "2024-01-01T12:00:01Z "+details("task1")+" ERROR first\n")...)
+	logs = append(logs, frame(2, "2024-01-01T12:00:03Z "+details("task2")+" ERROR second\n")...)
+	logs = append(logs, frame(1, "2024-01-01T12:00:02Z "+details("task1")+" INFO late line\n")...)
+	logs = append(logs, frame(2, "2024-01-01T12:00:04Z "+details("task3")+" ERROR gone task\n")...)
+
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	scanner := watcher.newLogScanner(service.ID, &docker.LogStream{ReadCloser: io.NopCloser(bytes.NewReader(logs)), Multiplexed: true})
+	if _, err := watcher.scanLogs(context.Background(), serviceSource(service), scanner, since); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(watcher.C) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(watcher.C))
+	}
+
+	expected := []struct {
+		name    string
+		slot    int
+		content string
+	}{
+		{"shop_web.1", 1, "ERROR first"},
+		{"shop_web.2", 2, "ERROR second"},
+		{"shop_web.0", 0, "ERROR gone task"},
+	}
+	for i, want := range expected {
+		m := <-watcher.C
+		if m.Container.Name != want.name || m.Container.TaskSlot != want.slot || m.Container.Service != "shop_web" {
+			t.Errorf("match %d: unexpected container %+v", i, m.Container)
+		}
+		if string(m.Line.Content) != want.content {
+			t.Errorf("match %d: expected %q, got %q", i, want.content, m.Line.Content)
+		}
+		if m.Container.Host != "manager-1" || m.Container.Image != "shop/web:1.2" {
+			t.Errorf("match %d: expected service details, got %+v", i, m.Container)
+		}
+	}
+
+	// Tasks are listed once for the first line and once for the unknown task.
+	if calls := client.TasksCallCount(); calls != 2 {
+		t.Errorf("expected 2 task listings, got %d", calls)
+	}
+
+	if offset := watcher.serviceOffsets[service.ID]; offset != "2024-01-01T12:00:04Z" {
+		t.Errorf("expected the offset to move forward only, got %s", offset)
+	}
+}
+
+func TestWatcher_scanLogs_serviceEvents(t *testing.T) {
+	service := container.Service{ID: "service1", Name: "shop_web"}
+
+	type line struct {
+		task    string
+		content string
+	}
+	type serviceMatch struct {
+		slot    int
+		content string
+		context []string
+	}
+
+	tests := []struct {
+		name  string
+		opts  WatcherOptions
+		lines []line
+		// want holds the task slot, content and context of every match,
+		// matched context lines marked by ">".
+		want []serviceMatch
+	}{
+		{
+			name: "multi-line events per task",
+			opts: WatcherOptions{ErrorPatterns: []string{"ERROR"}, MultilinePreset: "python"},
+			lines: []line{
+				{"task1", "ERROR request failed"},
+				{"task1", "Traceback (most recent call last):"},
+				{"task2", "INFO healthy"},
+				{"task1", `  File "app.py", line 3, in <module>`},
+				{"task1", "ValueError: invalid literal"},
+				{"task1", "INFO next request"},
+			},
+			want: []serviceMatch{{
+				slot:    1,
+				content: "ERROR request failed\nTraceback (most recent call last):\n  File \"app.py\", line 3, in <module>\nValueError: invalid literal",
+			}},
+		},
+		{
+			name: "context lines per task",
+			opts: WatcherOptions{ErrorPatterns: []string{"ERROR"}, ContextBefore: 1, ContextAfter: 1},
+			lines: []line{
+				{"task1", "INFO connecting"},
+				{"task2", "INFO other task"},
+				{"task1", "ERROR connection refused"},
+				{"task2", "ERROR disk full"},
+				{"task1", "INFO retrying"},
+			},
+			want: []serviceMatch{
+				{slot: 1, content: "ERROR connection refused", context: []string{"INFO connecting", "> ERROR connection refused", "INFO retrying"}},
+				{slot: 2, content: "ERROR disk full", context: []string{"INFO other task", "> ERROR disk full"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockContainerClient()
+			client.SetTasks(service.ID, []container.Task{
+				{ID: "task1", ServiceID: service.ID, Slot: 1},
+				{ID: "task2", ServiceID: service.ID, Slot: 2},
+			})
+
+			opts := tt.opts
+			opts.Interval = time.Second
+			opts.SwarmServices = true
+			watcher, err := New(client, &opts)
+			if err != nil {
+				t.Fatalf("failed to create watcher: %v", err)
+			}
+			watcher.C = make(chan *MatchedLog, 10)
+
+			var logs []byte
+			for i, l := range tt.lines {
+				details := "com.docker.swarm.service.id=service1,com.docker.swarm.task.id=" + l.task
+				logs = append(logs, frame(1, fmt.Sprintf("2024-01-01T12:00:%02dZ %s %s\n", i+1, details, l.content))...)
+			}
+
+			since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			scanner := watcher.newLogScanner(service.ID, &docker.LogStream{ReadCloser: io.NopCloser(bytes.NewReader(logs)), Multiplexed: true})
+			if _, err := watcher.scanLogs(context.Background(), serviceSource(service), scanner, since); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			close(watcher.C)
+
+			var got []serviceMatch
+			for m := range watcher.C {
+				match := serviceMatch{slot: m.Container.TaskSlot, content: string(m.Line.Content)}
+				for _, line := range m.Context {
+					text := string(line.Line.Content)
+					if line.Matched {
+						text = "> " + text
+					}
+					match.context = append(match.context, text)
+				}
+				got = append(got, match)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected matches %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWatcher_syncServices(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetServices([]container.Service{{ID: "service1", Name: "shop_web"}})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Second,
+		ErrorPatterns: []string{"ERROR"},
+		SwarmServices: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := watcher.syncServices(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watcher.mu.RLock()
+	_, following := watcher.serviceStreams["service1"]
+	_, hasOffset := watcher.serviceOffsets["service1"]
+	watcher.mu.RUnlock()
+	if !following || !hasOffset {
+		t.Fatal("expected the service to be followed")
+	}
+
+	client.SetServices(nil)
+	if err := watcher.syncServices(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	watcher.mu.RLock()
+	_, following = watcher.serviceStreams["service1"]
+	watcher.mu.RUnlock()
+	if following {
+		t.Error("expected the stream of the removed service to be closed")
+	}
+
+	cancel()
+	watcher.wg.Wait()
+}
+
+func TestWatcher_syncContainers_skipsServiceTasks(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetContainers([]container.Container{
+		{ID: "container1", Name: "standalone"},
+		{ID: "container2", Name: "shop_web.1.abcdef", Labels: map[string]string{container.LabelSwarmServiceID: "service1"}},
+	})
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Second,
+		ErrorPatterns: []string{"ERROR"},
+		SwarmServices: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	if err := watcher.syncContainers(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := watcher.containers["container2"]; ok {
+		t.Error("expected the task container to be watched through its service")
+	}
+	if _, ok := watcher.containers["container1"]; !ok {
+		t.Error("expected the standalone container to be watched")
+	}
+}
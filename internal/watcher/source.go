@@ -0,0 +1,146 @@
+package watcher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/docker"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// logSource is the container or Swarm service whose logs scanLogs reads.
+type logSource struct {
+	container container.Container
+	// service is set for the logs of a Swarm service. Every line then carries
+	// log details naming the task it came from, and is attributed to that
+	// task.
+	service *container.Service
+}
+
+func containerSource(c container.Container) logSource {
+	return logSource{container: c}
+}
+
+func serviceSource(s container.Service) logSource {
+	return logSource{container: container.Container{ID: s.ID, Labels: s.Labels}, service: &s}
+}
+
+func (s logSource) id() string {
+	return s.container.ID
+}
+
+func (s logSource) logAttr() slog.Attr {
+	if s.service != nil {
+		return slog.String("serviceID", s.id())
+	}
+	return slog.String("containerID", s.id())
+}
+
+// logReader parses the lines of a source, advances its offset, and hands
+// every line to the matchCollector of the container it is attributed to.
+type logReader struct {
+	w     *Watcher
+	src   logSource
+	since time.Time
+	// last is the timestamp of the newest line read.
+	last time.Time
+
+	collectors map[string]*matchCollector
+	// order lists the collectors as they were created, so that pending
+	// matches are flushed in a stable order.
+	order []*matchCollector
+}
+
+func (w *Watcher) newLogReader(src logSource, since time.Time) *logReader {
+	return &logReader{
+		w:          w,
+		src:        src,
+		since:      since,
+		last:       since,
+		collectors: make(map[string]*matchCollector),
+	}
+}
+
+// read parses a raw log line and returns it with the collector of its
+// container. Lines not newer than since are skipped, because the daemon may
+// repeat lines written at the very moment of the last offset. Lines of
+// different tasks of a service may arrive out of order, so the offset only
+// moves forward. It reports false for lines that must be skipped.
+func (r *logReader) read(ctx context.Context, stream logfilter.Stream, raw []byte) (*matchCollector, *logfilter.MatchedLine, bool) {
+	timestamp, content, err := r.w.parseLogLine(raw)
+	if err != nil {
+		slog.Debug("Skipping malformed log line", r.src.logAttr(), "err", err)
+		return nil, nil, false
+	}
+	if timestamp == nil {
+		return nil, nil, false
+	}
+
+	ts, err := parseStrSince(string(timestamp))
+	if err != nil {
+		slog.Debug("Skipping log line without timestamp", r.src.logAttr(), "err", err)
+		return nil, nil, false
+	}
+	if !ts.After(r.since) {
+		return nil, nil, false
+	}
+
+	if ts.After(r.last) {
+		r.last = ts
+		if r.src.service != nil {
+			r.w.setServiceOffset(r.src.id(), string(timestamp))
+		} else {
+			r.w.setOffset(r.src.id(), string(timestamp))
+		}
+	}
+
+	matches, content, ok := r.collector(ctx, content)
+	if !ok {
+		return nil, nil, false
+	}
+	return matches, &logfilter.MatchedLine{Timestamp: timestamp, Content: content, Stream: stream}, true
+}
+
+// collector returns the collector of the container the line content is
+// attributed to, and the content without the log details of a service.
+func (r *logReader) collector(ctx context.Context, content []byte) (*matchCollector, []byte, bool) {
+	service := r.src.service
+	if service == nil {
+		return r.collectorOf("", func() container.Container { return r.src.container }), content, true
+	}
+
+	details, message, err := docker.ParseLogDetails(content)
+	if err != nil {
+		slog.Debug("Skipping log line without details", r.src.logAttr(), "err", err)
+		return nil, nil, false
+	}
+
+	taskID := details[container.LabelSwarmTaskID]
+	matches := r.collectorOf(taskID, func() container.Container {
+		return container.TaskContainer(*service, r.w.serviceTask(ctx, service.ID, taskID))
+	})
+	return matches, message, true
+}
+
+func (r *logReader) collectorOf(key string, container func() container.Container) *matchCollector {
+	matches, ok := r.collectors[key]
+	if !ok {
+		matches = r.w.newMatchCollector(container(), r.src.id())
+		r.collectors[key] = matches
+		r.order = append(r.order, matches)
+	}
+	return matches
+}
+
+// flush sends what the collectors hold back: pending multi-line events and
+// matches waiting for their after context.
+func (r *logReader) flush(ctx context.Context) error {
+	for _, matches := range r.order {
+		if err := matches.flushPending(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package watcher
+
+import (
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+// maxThresholdSamples is the number of earlier matches sent along with the
+// match that crosses a threshold.
+const maxThresholdSamples = 3
+
+type thresholdState struct {
+	// matches are the times of the matches within the window, oldest first.
+	matches []time.Time
+	samples []thresholdSample
+}
+
+type thresholdSample struct {
+	at   time.Time
+	line *logfilter.MatchedLine
+}
+
+// crossesThreshold counts a match of a threshold rule and reports whether it
+// brings the matches of the rule within its window to the threshold. The
+// count then starts over, and m gets the count and sample lines. Matches of
+// other rules always cross. The time of a match is the timestamp of its
+// line, so that a batch of polled lines is counted as it was written.
+func (w *Watcher) crossesThreshold(id string, m *MatchedLog) bool {
+	rule := m.Rule
+	if rule == nil || rule.Threshold <= 1 {
+		return true
+	}
+
+	at, err := parseStrSince(string(m.Line.Timestamp))
+	if err != nil {
+		at = time.Now()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.counts == nil {
+		w.counts = make(map[string]map[*logfilter.Rule]*thresholdState)
+	}
+	if w.counts[id] == nil {
+		w.counts[id] = make(map[*logfilter.Rule]*thresholdState)
+	}
+	state, ok := w.counts[id][rule]
+	if !ok {
+		state = &thresholdState{}
+		w.counts[id][rule] = state
+	}
+
+	cutoff := at.Add(-rule.Window)
+	for len(state.matches) > 0 && state.matches[0].Before(cutoff) {
+		state.matches = state.matches[1:]
+	}
+	for len(state.samples) > 0 && state.samples[0].at.Before(cutoff) {
+		state.samples = state.samples[1:]
+	}
+
+	state.matches = append(state.matches, at)
+	if len(state.matches) < rule.Threshold {
+		state.samples = append(state.samples, thresholdSample{at: at, line: m.Line})
+		if len(state.samples) > maxThresholdSamples {
+			state.samples = state.samples[len(state.samples)-maxThresholdSamples:]
+		}
+		return false
+	}
+
+	m.Count = len(state.matches)
+	for _, sample := range state.samples {
+		m.Samples = append(m.Samples, sample.line)
+	}
+	delete(w.counts[id], rule)
+	return true
+}
@@ -0,0 +1,87 @@
+package watcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+)
+
+func TestWatcher_processContainerLogs_threshold(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	// The window is one minute, so the first two resets expire before the
+	// third one arrives.
+	polls := [][]string{
+		{
+			"2023-03-15T12:00:00.000000000Z connection reset by peer (1)",
+			"2023-03-15T12:00:10.000000000Z connection reset by peer (2)",
+			"2023-03-15T12:01:30.000000000Z connection reset by peer (3)",
+		},
+		{
+			"2023-03-15T12:01:40.000000000Z connection reset by peer (4)",
+			"2023-03-15T12:01:45.000000000Z ERROR: payment failed",
+			"2023-03-15T12:01:50.000000000Z connection reset by peer (5)",
+			"2023-03-15T12:01:55.000000000Z connection reset by peer (6)",
+		},
+	}
+
+	client := NewMockContainerClient()
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Second,
+		ErrorPatterns: []string{"ERROR"},
+		Rules: []logfilter.RuleConfig{
+			{Name: "resets", Patterns: []string{"connection reset"}, Threshold: 3, Window: "1m"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 10)
+	watcher.offsets[c.ID] = "2023-03-15T11:59:00.000000000Z"
+
+	var logs []string
+	for _, poll := range polls {
+		logs = append(logs, poll...)
+		client.SetLogs(c.ID, []byte(strings.Join(logs, "\n")))
+		if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+			t.Fatalf("processContainerLogs failed: %v", err)
+		}
+	}
+	close(watcher.C)
+
+	var got []*MatchedLog
+	for match := range watcher.C {
+		got = append(got, match)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(got))
+	}
+
+	if string(got[0].Line.Content) != "ERROR: payment failed" || got[0].Count != 0 {
+		t.Errorf("expected the error pattern match without a count, got %q (count %d)", got[0].Line.Content, got[0].Count)
+	}
+
+	resets := got[1]
+	if string(resets.Line.Content) != "connection reset by peer (5)" {
+		t.Errorf("expected the match crossing the threshold, got %q", resets.Line.Content)
+	}
+	if resets.Count != 3 {
+		t.Errorf("expected count 3, got %d", resets.Count)
+	}
+	var samples []string
+	for _, sample := range resets.Samples {
+		samples = append(samples, string(sample.Content))
+	}
+	if want := "connection reset by peer (3),connection reset by peer (4)"; strings.Join(samples, ",") != want {
+		t.Errorf("expected samples %s, got %v", want, samples)
+	}
+
+	// The count starts over after the threshold is crossed.
+	if state := watcher.counts[c.ID][resets.Rule]; state == nil || len(state.matches) != 1 {
+		t.Errorf("expected one match counted after the alert, got %+v", state)
+	}
+}
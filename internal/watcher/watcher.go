@@ -5,40 +5,191 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
 	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
+	"github.com/andvarfolomeev/docker-notifier/internal/redact"
 )
 
+// maxConsecutiveFailures is the number of failed checks or polls in a row
+// after which the Docker host is lost.
 const maxConsecutiveFailures = 5
 
 type MatchedLog struct {
 	Container container.Container
 	Line      *logfilter.MatchedLine
+	// Rule is the rule that fired, nil when a field rule matched.
+	Rule *logfilter.Rule
+	// Context are the lines around the match, the match included, when the
+	// rule asks for them. Matches with overlapping context are sent as one
+	// MatchedLog, which is then the most severe of them.
+	Context []ContextLine
+	// Count is the number of matches within the window of a threshold rule,
+	// and Samples are some of the matches before Line.
+	Count   int
+	Samples []*logfilter.MatchedLine
+}
+
+// Severity is the severity of the rule that fired, SeverityError for field
+// rules.
+func (m *MatchedLog) Severity() logfilter.Severity {
+	if m.Rule == nil {
+		return logfilter.SeverityError
+	}
+	return m.Rule.Severity
 }
 
 type Watcher struct {
 	client   ContainerClient
 	interval time.Duration
-	patterns []*regexp.Regexp
+	matcher  *logfilter.Matcher
+	follow   bool
 	C        chan *MatchedLog
 
+	maxLineLength int
+	sanitize      bool
+	pollMaxLines  int
+	pollMaxBytes  int64
+
+	multiline *logfilter.MultilineOptions
+	redactor  *redact.Redactor
+
+	crashAlerts   bool
+	crashLogLines int
+	Crashes       chan *Crash
+
+	crashLoopThreshold      int
+	crashLoopWindow         time.Duration
+	crashLoopUpdateInterval time.Duration
+	CrashLoops              chan *CrashLoop
+	now                     func() time.Time
+
+	healthAlerts bool
+	Health       chan *HealthChange
+
+	host       string
+	hostAlerts bool
+	Hosts      chan *HostStatus
+
+	swarmServices  bool
+	services       map[string]container.Service
+	serviceOffsets map[string]string
+	serviceStreams map[string]*stream
+	tasks          map[string]container.Task
+
 	mu      sync.RWMutex
 	offsets map[string]string
+	// counts are the recent matches of threshold rules per container.
+	counts     map[string]map[*logfilter.Rule]*thresholdState
+	containers map[string]container.Container
+	streams    map[string]*stream
+	exits      map[string]exitReason
+
+	loops        map[string]*loopState
+	recentErrors map[string][]*logfilter.MatchedLine
+	health       map[string]string
+
+	wg sync.WaitGroup
 }
 
 type WatcherOptions struct {
-	Interval      time.Duration
+	Interval time.Duration
+	// ErrorPatterns are shorthands for rules of error severity, named after
+	// the pattern.
 	ErrorPatterns []string
+	// ContextBefore and ContextAfter are the numbers of lines around a match
+	// of an error pattern included in the alert.
+	ContextBefore int
+	ContextAfter  int
+	Rules         []logfilter.RuleConfig
+	// RuleExcludes maps a rule name to additional patterns that suppress its
+	// matches.
+	RuleExcludes map[string][]string
+	// ExcludePatterns suppress matches of every rule. See logfilter.Matcher
+	// for the evaluation order.
+	ExcludePatterns []string
+	// Follow keeps one streaming log connection per container instead of
+	// polling logs every Interval.
+	Follow bool
+	// MaxLineLength cuts longer log lines before matching. Zero keeps lines
+	// whole.
+	MaxLineLength int
+	// Sanitize strips ANSI escape sequences and control characters from log
+	// lines before matching, see logfilter.Sanitize.
+	Sanitize bool
+	// PollMaxLines and PollMaxBytes limit the log output read from a
+	// container in one poll. The rest of the output is skipped, so that a
+	// chatty container does not hold up the others. Zero means no limit.
+	PollMaxLines int
+	PollMaxBytes int64
+	// MultilinePreset, MultilineStart and MultilineContinuation group stack
+	// traces and other continuation lines into one event before matching, see
+	// logfilter.NewMultilineOptions.
+	MultilinePreset       string
+	MultilineStart        string
+	MultilineContinuation string
+	// MultilineMaxLines limits the lines kept per event.
+	MultilineMaxLines int
+	// LogFormat is the default format of log lines, "text", "json" or
+	// "logfmt". Containers select another one with the LabelLogFormat label.
+	// Structured lines are matched against FieldRules when there are any,
+	// other lines against ErrorPatterns.
+	LogFormat  string
+	FieldRules []string
+	// AlertFields are the fields of structured lines shown in alerts in place
+	// of the raw line.
+	AlertFields []string
+	// Redact names the built-in detectors of secrets and personal data, see
+	// redact.Detectors, and RedactPatterns are additional patterns. What they
+	// find is masked in every log line and healthcheck output the watcher
+	// sends.
+	Redact         []string
+	RedactPatterns []string
+	// CrashAlerts enables a Crash on Crashes for every watched container that
+	// exits with a non-zero code or is OOM-killed.
+	CrashAlerts bool
+	// CrashLogLines is the number of last log lines included in a Crash.
+	CrashLogLines int
+	// CrashLoopThreshold is the number of restarts within CrashLoopWindow
+	// that makes a container crash looping. Zero disables the detection.
+	CrashLoopThreshold int
+	CrashLoopWindow    time.Duration
+	// CrashLoopUpdateInterval is the minimum time between CrashLoop updates
+	// while a container keeps restarting.
+	CrashLoopUpdateInterval time.Duration
+	// HealthAlerts enables a HealthChange on Health when a watched container
+	// turns unhealthy or recovers.
+	HealthAlerts bool
+	// Host names the Docker endpoint in HostStatus alerts, and selects the
+	// rules restricted to endpoints that apply.
+	Host string
+	// HostAlerts enables a HostStatus on Hosts when the daemon stops
+	// answering and when it is reachable again.
+	HostAlerts bool
+	// SwarmServices streams the logs of every Swarm service and attributes
+	// matches to the service and task slot. Task containers are then not
+	// watched on their own.
+	SwarmServices bool
 }
 
 func New(
 	client ContainerClient,
 	opts *WatcherOptions,
 ) (*Watcher, error) {
-	patterns, err := compileErrorPatterns(opts.ErrorPatterns)
+	matcher, err := newMatcher(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	multiline, err := logfilter.NewMultilineOptions(opts.MultilinePreset, opts.MultilineStart, opts.MultilineContinuation, opts.MultilineMaxLines)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := redact.New(opts.Redact, opts.RedactPatterns)
 	if err != nil {
 		return nil, err
 	}
@@ -48,11 +199,49 @@ func New(
 	c := make(chan *MatchedLog)
 
 	w := &Watcher{
-		client:   client,
-		interval: opts.Interval,
-		patterns: patterns,
-		offsets:  offsets,
-		C:        c,
+		client:     client,
+		interval:   opts.Interval,
+		matcher:    matcher,
+		follow:     opts.Follow,
+		multiline:  multiline,
+		redactor:   redactor,
+		offsets:    offsets,
+		counts:     make(map[string]map[*logfilter.Rule]*thresholdState),
+		containers: make(map[string]container.Container),
+		streams:    make(map[string]*stream),
+		exits:      make(map[string]exitReason),
+		C:          c,
+
+		maxLineLength: opts.MaxLineLength,
+		sanitize:      opts.Sanitize,
+		pollMaxLines:  opts.PollMaxLines,
+		pollMaxBytes:  opts.PollMaxBytes,
+
+		crashAlerts:   opts.CrashAlerts,
+		crashLogLines: opts.CrashLogLines,
+		Crashes:       make(chan *Crash),
+
+		crashLoopThreshold:      opts.CrashLoopThreshold,
+		crashLoopWindow:         opts.CrashLoopWindow,
+		crashLoopUpdateInterval: opts.CrashLoopUpdateInterval,
+		CrashLoops:              make(chan *CrashLoop),
+		now:                     time.Now,
+		loops:                   make(map[string]*loopState),
+		recentErrors:            make(map[string][]*logfilter.MatchedLine),
+
+		healthAlerts: opts.HealthAlerts,
+		Health:       make(chan *HealthChange),
+		health:       make(map[string]string),
+
+		host:       opts.Host,
+		hostAlerts: opts.HostAlerts,
+		Hosts:      make(chan *HostStatus),
+
+		swarmServices:  opts.SwarmServices,
+		services:       make(map[string]container.Service),
+		serviceOffsets: make(map[string]string),
+		serviceStreams: make(map[string]*stream),
+		tasks:          make(map[string]container.Task),
 	}
 
 	return w, nil
@@ -61,7 +250,9 @@ func New(
 func (w *Watcher) Start(parentCtx context.Context) {
 	ctx, cancel := context.WithCancel(parentCtx)
 
+	w.wg.Add(1)
 	go func() {
+		defer w.wg.Done()
 		defer cancel()
 		w.start(ctx)
 	}()
@@ -71,21 +262,61 @@ func (w *Watcher) start(ctx context.Context) {
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
 
-	consecutiveFailures := 0
+	var (
+		events <-chan container.Event
+		errs   <-chan error
+		synced bool
+	)
+
+	var health hostHealth
 
 	for {
 		select {
 		case <-ticker.C:
+			if w.swarmServices {
+				if err := w.syncServices(ctx); err != nil {
+					slog.Error("Failed to check services", "host", w.host, "err", err)
+				}
+			}
+
+			if synced {
+				if !w.follow {
+					if err := w.processContainers(ctx); err != nil {
+						slog.Error("Failed to poll containers", "host", w.host, "err", err)
+						w.hostFailed(ctx, &health, err)
+					} else {
+						w.hostAnswered(ctx, &health)
+					}
+				}
+				continue
+			}
+
+			// Subscribe before listing so that no event between the list
+			// and the subscription is lost.
+			if events == nil {
+				events, errs = w.client.ContainerEvents(ctx)
+			}
+
+			// Keep retrying on failures, so that watching resumes once the
+			// daemon is back.
 			if err := w.checkContainers(ctx); err != nil {
-				consecutiveFailures++
-			} else {
-				consecutiveFailures = 0
+				slog.Error("Failed to check containers", "host", w.host, "err", err)
+				w.hostFailed(ctx, &health, err)
+				continue
 			}
 
-			if consecutiveFailures >= maxConsecutiveFailures {
-				slog.Error("Too many consecutive failures")
-				return
+			synced = true
+			w.hostAnswered(ctx, &health)
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
 			}
+			w.handleEvent(ctx, event)
+		case err := <-errs:
+			slog.Error("Container event stream failed, resyncing", "err", err)
+			events, errs = nil, nil
+			synced = false
 		case <-ctx.Done():
 			return
 		}
@@ -93,22 +324,201 @@ func (w *Watcher) start(ctx context.Context) {
 
 }
 
+// checkContainers replaces the known container set with a fresh listing and
+// processes logs of every container in it.
 func (w *Watcher) checkContainers(ctx context.Context) error {
+	if err := w.syncContainers(ctx); err != nil {
+		return err
+	}
+
+	if !w.follow {
+		return w.processContainers(ctx)
+	}
+
+	return nil
+}
+
+func (w *Watcher) syncContainers(ctx context.Context) error {
 	containers, err := w.client.RunningContainers(ctx)
 	if err != nil {
 		return fmt.Errorf("Failed to list containers: %w", err)
 	}
 
+	running := make(map[string]container.Container, len(containers))
+	for _, container := range containers {
+		if w.isServiceTask(container) {
+			continue
+		}
+		running[container.ID] = container
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id := range w.containers {
+		if _, ok := running[id]; !ok {
+			delete(w.offsets, id)
+			delete(w.counts, id)
+			w.stopFollowing(id)
+		}
+	}
+
+	for id := range running {
+		if _, ok := w.offsets[id]; !ok {
+			w.offsets[id] = nowStrSince()
+		}
+	}
+
+	w.containers = running
+
+	if w.follow {
+		for id := range running {
+			w.startFollowing(ctx, id)
+		}
+	}
+
+	return nil
+}
+
+// processContainers polls the logs of every known container. It fails when
+// the logs of none of them could be read, which is taken as a sign that the
+// daemon is not answering.
+func (w *Watcher) processContainers(ctx context.Context) error {
+	w.mu.RLock()
+	containers := make([]container.Container, 0, len(w.containers))
+	for _, container := range w.containers {
+		containers = append(containers, container)
+	}
+	w.mu.RUnlock()
+
+	var lastErr error
+	failed := 0
 	for _, container := range containers {
 		if err := w.processContainerLogs(ctx, container); err != nil {
 			slog.Error("Failed to process container logs", "err", err)
+			lastErr = err
+			failed++
 		}
-
 	}
 
+	if failed > 0 && failed == len(containers) {
+		return lastErr
+	}
 	return nil
 }
 
+func (w *Watcher) handleEvent(ctx context.Context, event container.Event) {
+	id := event.Container.ID
+
+	w.mu.RLock()
+	known, ok := w.containers[id]
+	w.mu.RUnlock()
+
+	switch event.Action {
+	case container.EventStart:
+		if ok || w.isServiceTask(event.Container) {
+			return
+		}
+		slog.Debug("Container started", "containerID", id, "name", event.Container.Name)
+
+		started := event.Container
+		if inspected, err := w.client.ContainerInspect(ctx, id); err != nil {
+			slog.Debug("Failed to inspect started container", "containerID", id, "err", err)
+		} else {
+			started = *inspected
+		}
+
+		w.mu.Lock()
+		w.containers[id] = started
+		// Scan logs from the moment the container started, so lines printed
+		// before the next tick are not skipped.
+		w.offsets[id] = event.Time.Format(time.RFC3339Nano)
+		if w.follow {
+			w.startFollowing(ctx, id)
+		}
+		w.mu.Unlock()
+
+		if w.crashLoopThreshold > 0 {
+			w.recordRestart(ctx, started, event.Time)
+		}
+	case container.EventDie, container.EventDestroy:
+		if event.Action == container.EventDestroy {
+			w.mu.Lock()
+			delete(w.loops, id)
+			delete(w.recentErrors, id)
+			delete(w.health, id)
+			w.mu.Unlock()
+		}
+
+		if !ok {
+			return
+		}
+		slog.Debug("Container stopped", "containerID", id, "action", event.Action)
+
+		// Pick up whatever the container printed since the last tick. A log
+		// stream ends on its own once the daemon has sent the last lines.
+		if event.Action == container.EventDie && !w.follow {
+			if err := w.processContainerLogs(ctx, known); err != nil {
+				slog.Error("Failed to process container logs", "err", err)
+			}
+		}
+
+		if event.Action == container.EventDie {
+			reason := w.takeExitReason(id)
+
+			if w.crashAlerts && !w.isLooping(id) {
+				w.reportCrash(ctx, known, event, reason)
+			}
+
+			if w.crashLoopThreshold > 0 && !reason.killed {
+				w.mu.Lock()
+				w.recordDeath(id)
+				w.mu.Unlock()
+			}
+		}
+
+		w.mu.Lock()
+		delete(w.containers, id)
+		delete(w.exits, id)
+		if event.Action == container.EventDestroy || !w.follow {
+			delete(w.offsets, id)
+			delete(w.counts, id)
+			w.stopFollowing(id)
+		}
+		w.mu.Unlock()
+	case container.EventRename:
+		if !ok {
+			return
+		}
+		known.Name = event.Container.Name
+
+		w.mu.Lock()
+		w.containers[id] = known
+		w.mu.Unlock()
+	case container.EventHealthStatus:
+		if !ok || !w.healthAlerts {
+			return
+		}
+		w.reportHealth(ctx, known, event.Status)
+	case container.EventOOM, container.EventKill:
+		if !ok {
+			return
+		}
+
+		w.mu.Lock()
+		reason := w.exits[id]
+		if event.Action == container.EventOOM {
+			reason.oomKilled = true
+		} else {
+			reason.killed = true
+		}
+		w.exits[id] = reason
+		w.mu.Unlock()
+	default:
+		slog.Debug("Container event", "containerID", id, "action", event.Action, "status", event.Status)
+	}
+}
+
 func (w *Watcher) processContainerLogs(ctx context.Context, container container.Container) error {
 	w.mu.RLock()
 	since, ok := w.offsets[container.ID]
@@ -122,64 +532,204 @@ func (w *Watcher) processContainerLogs(ctx context.Context, container container.
 		return nil
 	}
 
-	lines, err := w.client.ContainerLogs(ctx, container.ID, since, 0)
+	sinceTime, err := parseStrSince(since)
 	if err != nil {
-		return fmt.Errorf("Failed to to get logs for container %s: %w", container.ID, err)
+		return fmt.Errorf("Failed to process logs for container %s: %w", container.ID, err)
 	}
 
-	matchedLogs, err := logfilter.FindMatchedLines(w.patterns, lines)
+	polled := time.Now()
+
+	logs, err := w.client.ContainerLogs(ctx, container.ID, since, 0)
 	if err != nil {
-		return fmt.Errorf("Failed to process logs for container %s: %w", container.ID, err)
+		return fmt.Errorf("Failed to to get logs for container %s: %w", container.ID, err)
 	}
+	defer logs.Close()
 
-	sinceTime, err := parseStrSince(since)
+	scanner := w.newPollScanner(container.ID, logs)
+	last, err := w.scanLogs(ctx, containerSource(container), scanner, sinceTime)
 	if err != nil {
 		return fmt.Errorf("Failed to process logs for container %s: %w", container.ID, err)
 	}
 
-	for _, matchedLog := range matchedLogs {
-		lastSinceTime, err := parseStrSince(string(matchedLog.Timestamp))
-		if err != nil {
-			return fmt.Errorf("Failed to process logs for container %s: %w", container.ID, err)
+	if scanner.limited {
+		// Skip what is left of this poll, the next one starts with the
+		// lines written since the logs were requested.
+		if polled.After(last) {
+			w.setOffset(container.ID, polled.Format(time.RFC3339Nano))
 		}
+		slog.Warn("Log output truncated, skipping the rest of it", "containerID", container.ID, "container", container.Name, "lines", scanner.lines, "bytes", scanner.bytes, "skippedFrom", last)
+	}
 
-		if !lastSinceTime.After(sinceTime) {
+	return nil
+}
+
+// scanLogs matches every line of the scanner against the error patterns and
+// sends the matches to C. The offset of the source advances with every line
+// read, and the timestamp of the newest line is returned.
+func (w *Watcher) scanLogs(ctx context.Context, src logSource, scanner *logScanner, since time.Time) (time.Time, error) {
+	r := w.newLogReader(src, since)
+
+	if w.multiline != nil || w.withContext() {
+		return w.scanEvents(ctx, scanner, r)
+	}
+
+	for scanner.Scan() {
+		matches, line, ok := r.read(ctx, scanner.logStream(), scanner.Line())
+		if !ok {
 			continue
 		}
 
-		m := &MatchedLog{
-			Container: container,
-			Line:      matchedLog,
+		if err := matches.add(ctx, line); err != nil {
+			return r.last, err
 		}
+	}
 
-		select {
-		case w.C <- m:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+	return r.last, scanner.Err()
+}
+
+// parseLogLine is logfilter.ParseLogLine, with the content sanitized unless
+// disabled.
+func (w *Watcher) parseLogLine(line []byte) (timestamp, content []byte, err error) {
+	timestamp, content, err = logfilter.ParseLogLine(line)
+	if err == nil && w.sanitize {
+		content = logfilter.Sanitize(content)
 	}
+	return timestamp, content, err
+}
 
-	if len(matchedLogs) > 0 {
-		newOffset := string(matchedLogs[len(matchedLogs)-1].Timestamp)
+// send sends the match to C, unless the container is crash looping.
+func (w *Watcher) send(ctx context.Context, m *MatchedLog) error {
+	if w.crashLoopThreshold > 0 && w.isLooping(m.Container.ID) {
+		return nil
+	}
 
-		w.mu.Lock()
-		w.offsets[container.ID] = newOffset
-		w.mu.Unlock()
+	select {
+	case w.C <- m:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	return nil
+// Evaluate decides whether the log line content the container wrote to the
+// stream is an error.
+func (w *Watcher) Evaluate(container container.Container, stream logfilter.Stream, content []byte) logfilter.Decision {
+	return w.matcher.Evaluate(w.logFormat(container), stream, content)
 }
 
+// logFormat returns the log format selected by the container label, or the
+// default one.
+func (w *Watcher) logFormat(container container.Container) logfilter.Format {
+	label := container.LogFormat()
+	if label == "" {
+		return w.matcher.Format
+	}
+
+	format, err := logfilter.ParseFormat(label)
+	if err != nil {
+		slog.Debug("Ignoring log format label", "containerID", container.ID, "err", err)
+		return w.matcher.Format
+	}
+	return format
+}
+
+func (w *Watcher) setOffset(id, offset string) {
+	w.mu.Lock()
+	w.offsets[id] = offset
+	w.mu.Unlock()
+}
+
+// Cleanup waits for the watcher goroutines to stop and closes C. The context
+// passed to Start must be canceled first.
 func (w *Watcher) Cleanup() {
+	w.wg.Wait()
 	close(w.C)
+	close(w.Crashes)
+	close(w.CrashLoops)
+	close(w.Health)
+	close(w.Hosts)
+}
+
+func newMatcher(opts *WatcherOptions) (*logfilter.Matcher, error) {
+	rules, err := compileRules(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := compileExcludePatterns(opts.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := logfilter.ParseFormat(opts.LogFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldRules, err := logfilter.ParseFieldRules(opts.FieldRules)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logfilter.Matcher{
+		Rules:       rules,
+		FieldRules:  fieldRules,
+		Excludes:    excludes,
+		Format:      format,
+		AlertFields: opts.AlertFields,
+	}, nil
+}
+
+// compileRules compiles the named rules, preceded by one case-insensitive
+// rule of error severity per error pattern, named after the pattern. Rules
+// restricted to other endpoints than opts.Host are checked but left out.
+func compileRules(opts *WatcherOptions) ([]*logfilter.Rule, error) {
+	configs := make([]logfilter.RuleConfig, 0, len(opts.ErrorPatterns)+len(opts.Rules))
+	for _, pattern := range opts.ErrorPatterns {
+		configs = append(configs, logfilter.RuleConfig{
+			Name:          pattern,
+			Patterns:      []string{pattern},
+			ContextBefore: opts.ContextBefore,
+			ContextAfter:  opts.ContextAfter,
+		})
+	}
+	configs = append(configs, opts.Rules...)
+
+	rules := make([]*logfilter.Rule, 0, len(configs))
+	names := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		if names[config.Name] {
+			return nil, fmt.Errorf("duplicate rule name '%s'", config.Name)
+		}
+		names[config.Name] = true
+
+		config.Exclude = append(slices.Clone(config.Exclude), opts.RuleExcludes[config.Name]...)
+
+		rule, err := logfilter.NewRule(config)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.Endpoints) > 0 && !slices.Contains(config.Endpoints, opts.Host) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	for name := range opts.RuleExcludes {
+		if !names[name] {
+			return nil, fmt.Errorf("exclude patterns given for unknown rule '%s'", name)
+		}
+	}
+
+	return rules, nil
 }
 
-func compileErrorPatterns(errorPatterns []string) ([]*regexp.Regexp, error) {
-	patterns := make([]*regexp.Regexp, 0, len(errorPatterns))
-	for _, pattern := range errorPatterns {
+func compileExcludePatterns(excludePatterns []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(excludePatterns))
+	for _, pattern := range excludePatterns {
 		re, err := regexp.Compile("(?i)" + pattern)
 		if err != nil {
-			return nil, fmt.Errorf("invalid error pattern '%s': %w", pattern, err)
+			return nil, fmt.Errorf("invalid exclude pattern '%s': %w", pattern, err)
 		}
 		patterns = append(patterns, re)
 	}
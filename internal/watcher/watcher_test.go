@@ -1,12 +1,17 @@
 package watcher
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/andvarfolomeev/docker-notifier/internal/container"
+	"github.com/andvarfolomeev/docker-notifier/internal/logfilter"
 )
 
 func TestNew(t *testing.T) {
@@ -33,6 +38,33 @@ func TestNew(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "invalid exclude pattern",
+			opts: &WatcherOptions{
+				Interval:        time.Second,
+				ErrorPatterns:   []string{"ERROR"},
+				ExcludePatterns: []string{"(unclosed"},
+			},
+			expectedError: true,
+		},
+		{
+			name: "duplicate rule name",
+			opts: &WatcherOptions{
+				Interval:      time.Second,
+				ErrorPatterns: []string{"ERROR"},
+				Rules:         []logfilter.RuleConfig{{Name: "ERROR", Patterns: []string{"FATAL"}}},
+			},
+			expectedError: true,
+		},
+		{
+			name: "excludes of an unknown error pattern",
+			opts: &WatcherOptions{
+				Interval:      time.Second,
+				ErrorPatterns: []string{"ERROR"},
+				RuleExcludes:  map[string][]string{"FATAL": {"shutdown"}},
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -56,8 +88,8 @@ func TestNew(t *testing.T) {
 				if w.interval != tt.opts.Interval {
 					t.Errorf("expected interval %v, got %v", tt.opts.Interval, w.interval)
 				}
-				if len(w.patterns) != len(tt.opts.ErrorPatterns) {
-					t.Errorf("expected %d patterns, got %d", len(tt.opts.ErrorPatterns), len(w.patterns))
+				if len(w.matcher.Rules) != len(tt.opts.ErrorPatterns) {
+					t.Errorf("expected %d patterns, got %d", len(tt.opts.ErrorPatterns), len(w.matcher.Rules))
 				}
 			}
 		})
@@ -125,7 +157,7 @@ func TestWatcher_processContainerLogs(t *testing.T) {
 			client.SetContainers([]container.Container{tt.container})
 			client.SetLogs(tt.container.ID, []byte(tt.logs))
 
-			patterns, err := compileErrorPatterns(tt.patterns)
+			matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: tt.patterns})
 			if err != nil {
 				t.Fatalf("failed to compile patterns: %v", err)
 			}
@@ -133,7 +165,7 @@ func TestWatcher_processContainerLogs(t *testing.T) {
 			watcher := &Watcher{
 				client:   client,
 				interval: time.Millisecond * 10,
-				patterns: patterns,
+				matcher:  matcher,
 				offsets:  make(map[string]string),
 				C:        make(chan *MatchedLog, 10),
 			}
@@ -239,16 +271,146 @@ func TestWatcher_Start(t *testing.T) {
 
 	time.Sleep(time.Millisecond * 10)
 
-	callCount := client.ContainersCallCount()
-	if callCount < 2 {
-		t.Errorf("expected at least 2 container checks, got %d", callCount)
+	if callCount := client.ContainersCallCount(); callCount != 1 {
+		t.Errorf("expected containers to be listed once, got %d", callCount)
+	}
+
+	if callCount := client.EventsCallCount(); callCount != 1 {
+		t.Errorf("expected a single event subscription, got %d", callCount)
+	}
+
+	if callCount := client.LogsCallCount(); callCount < 2 {
+		t.Errorf("expected at least 2 log checks, got %d", callCount)
+	}
+}
+
+func TestWatcher_handleEvent(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetLogs("container1", []byte("2023-03-15T12:02:00.000000000Z ERROR: Connection failed"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 10)
+
+	ctx := context.Background()
+	startedAt := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	watcher.handleEvent(ctx, container.Event{
+		Action:    container.EventStart,
+		Container: container.Container{ID: "container1", Name: "web"},
+		Time:      startedAt,
+	})
+
+	if _, ok := watcher.containers["container1"]; !ok {
+		t.Fatal("expected started container to be watched")
+	}
+	if offset := watcher.offsets["container1"]; offset != startedAt.Format(time.RFC3339Nano) {
+		t.Errorf("expected offset at start time, got %s", offset)
+	}
+
+	watcher.handleEvent(ctx, container.Event{
+		Action:    container.EventRename,
+		Container: container.Container{ID: "container1", Name: "web-renamed"},
+	})
+
+	if name := watcher.containers["container1"].Name; name != "web-renamed" {
+		t.Errorf("expected renamed container, got %s", name)
+	}
+
+	watcher.handleEvent(ctx, container.Event{
+		Action:    container.EventDie,
+		Container: container.Container{ID: "container1"},
+	})
+
+	if _, ok := watcher.containers["container1"]; ok {
+		t.Error("expected stopped container to be forgotten")
+	}
+	if _, ok := watcher.offsets["container1"]; ok {
+		t.Error("expected offset of stopped container to be dropped")
+	}
+
+	select {
+	case match := <-watcher.C:
+		if match.Container.Name != "web-renamed" {
+			t.Errorf("expected match from web-renamed, got %s", match.Container.Name)
+		}
+	default:
+		t.Error("expected logs to be flushed when the container dies")
+	}
+}
+
+func TestWatcher_Start_events(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetLogs("container1", []byte("2023-03-15T12:02:00.000000000Z ERROR: Connection failed"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 20,
+		ErrorPatterns: []string{"ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher.Start(ctx)
+
+	client.SendEvent(container.Event{
+		Action:    container.EventStart,
+		Container: container.Container{ID: "container1", Name: "web"},
+		Time:      time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC),
+	})
+
+	select {
+	case match := <-watcher.C:
+		if match.Container.ID != "container1" {
+			t.Errorf("expected match from container1, got %s", match.Container.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a match from the started container")
+	}
+
+	if callCount := client.ContainersCallCount(); callCount != 1 {
+		t.Errorf("expected containers to be listed once, got %d", callCount)
+	}
+}
+
+func TestWatcher_resyncOnEventError(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetEventsError(errors.New("stream closed"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	watcher.start(ctx)
+
+	if callCount := client.EventsCallCount(); callCount < 2 {
+		t.Errorf("expected event stream to be resubscribed, got %d subscriptions", callCount)
+	}
+
+	if callCount := client.ContainersCallCount(); callCount < 2 {
+		t.Errorf("expected containers to be relisted after stream failure, got %d", callCount)
 	}
 }
 
 func TestHelperFunctions(t *testing.T) {
-	t.Run("compileErrorPatterns", func(t *testing.T) {
+	t.Run("compileRules", func(t *testing.T) {
 		patterns := []string{"ERROR", "FATAL", "CRITICAL"}
-		compiled, err := compileErrorPatterns(patterns)
+		compiled, err := compileRules(&WatcherOptions{ErrorPatterns: patterns})
 
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
@@ -257,7 +419,7 @@ func TestHelperFunctions(t *testing.T) {
 			t.Errorf("expected %d patterns, got %d", len(patterns), len(compiled))
 		}
 
-		_, err = compileErrorPatterns([]string{"["})
+		_, err = compileRules(&WatcherOptions{ErrorPatterns: []string{"["}})
 		if err == nil {
 			t.Error("expected error for invalid pattern, got nil")
 		}
@@ -325,3 +487,465 @@ func TestWatcherConsecutiveFailures(t *testing.T) {
 		t.Errorf("expected at least %d container checks, got %d", maxConsecutiveFailures, callCount)
 	}
 }
+
+func frame(stream byte, payload string) []byte {
+	header := []byte{stream, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestWatcher_processContainerLogs_multiplexed(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	// The second frame is exactly 10 bytes long, so its header contains a
+	// newline byte, and the last line is split across two frames.
+	logs := bytes.Join([][]byte{
+		frame(1, "2023-03-15T12:01:00.000000000Z Processing request\n2023-03-15T12:02:00.000000000Z ERROR: first\n"),
+		frame(2, "2023-03-15"),
+		frame(2, "T12:03:00.000000000Z ERROR: second\n"),
+	}, nil)
+
+	client := NewMockContainerClient()
+	client.SetMultiplexedLogs(c.ID, logs)
+
+	matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: []string{"ERROR"}})
+	if err != nil {
+		t.Fatalf("failed to compile patterns: %v", err)
+	}
+
+	watcher := &Watcher{
+		client:  client,
+		matcher: matcher,
+		offsets: map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+		C:       make(chan *MatchedLog, 10),
+	}
+
+	if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+		t.Fatalf("processContainerLogs failed: %v", err)
+	}
+	close(watcher.C)
+
+	var got []string
+	for match := range watcher.C {
+		got = append(got, string(match.Line.Content))
+	}
+
+	want := []string{"ERROR: first", "ERROR: second"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	if offset := watcher.offsets[c.ID]; offset != "2023-03-15T12:03:00.000000000Z" {
+		t.Errorf("expected offset of the last line, got %s", offset)
+	}
+}
+
+func TestWatcher_follow(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetContainers([]container.Container{{ID: "container1", Name: "test-container1"}})
+	client.SetLogs("container1", []byte("2999-03-15T12:02:00.000000000Z ERROR: Connection failed"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+		Follow:        true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	watcher.Start(ctx)
+
+	select {
+	case match := <-watcher.C:
+		if string(match.Line.Content) != "ERROR: Connection failed" {
+			t.Errorf("unexpected match: %q", match.Line.Content)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a match from the log stream")
+	}
+
+	time.Sleep(time.Millisecond * 50)
+
+	if callCount := client.LogsCallCount(); callCount != 0 {
+		t.Errorf("expected no polling in follow mode, got %d log calls", callCount)
+	}
+
+	// The stream ended, so the watcher reconnects from the last timestamp and
+	// must not send the same line again.
+	time.Sleep(followReconnectDelay + time.Millisecond*100)
+
+	select {
+	case match := <-watcher.C:
+		t.Errorf("unexpected repeated match: %q", match.Line.Content)
+	default:
+	}
+
+	if callCount := client.FollowCallCount(); callCount < 2 {
+		t.Errorf("expected the stream to be reopened, got %d calls", callCount)
+	}
+
+	cancel()
+	watcher.Cleanup()
+}
+
+func TestWatcher_handleEvent_inspect(t *testing.T) {
+	client := NewMockContainerClient()
+	client.SetContainers([]container.Container{{
+		ID:     "container1",
+		Name:   "shop-web-1",
+		Image:  "shop/web:1.2",
+		Labels: map[string]string{container.LabelComposeProject: "shop"},
+		Host:   "docker-host-1",
+	}})
+	client.SetLogs("container1", []byte("2023-03-15T12:02:00.000000000Z ERROR: Connection failed"))
+
+	watcher, err := New(client, &WatcherOptions{
+		Interval:      time.Millisecond * 10,
+		ErrorPatterns: []string{"ERROR"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	watcher.C = make(chan *MatchedLog, 10)
+
+	ctx := context.Background()
+	watcher.handleEvent(ctx, container.Event{
+		Action:    container.EventStart,
+		Container: container.Container{ID: "container1", Name: "shop-web-1"},
+		Time:      time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC),
+	})
+	watcher.processContainers(ctx)
+
+	select {
+	case match := <-watcher.C:
+		if match.Container.Image != "shop/web:1.2" || match.Container.ComposeProject() != "shop" || match.Container.Host != "docker-host-1" {
+			t.Errorf("expected inspected metadata in the match, got %+v", match.Container)
+		}
+	default:
+		t.Fatal("expected a match")
+	}
+}
+
+func TestWatcher_Evaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     string
+		labels     map[string]string
+		fieldRules []string
+		content    string
+		want       bool
+		wantFields []logfilter.Field
+	}{
+		{
+			name:    "text line",
+			format:  "text",
+			content: `{"level":"error","msg":"payment failed"}`,
+			want:    true,
+		},
+		{
+			name:       "json line matched by field rules",
+			format:     "json",
+			fieldRules: []string{"level in (error, fatal)", "status >= 500"},
+			content:    `{"level":"info","msg":"request done","status":503,"trace_id":"abc"}`,
+			want:       true,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "request done"}, {Key: "trace_id", Value: "abc"}},
+		},
+		{
+			name:       "json line not matched by field rules",
+			format:     "json",
+			fieldRules: []string{"level in (error, fatal)"},
+			content:    `{"level":"info","msg":"ERROR count reset"}`,
+			want:       false,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "ERROR count reset"}},
+		},
+		{
+			name:       "json line without field rules uses patterns",
+			format:     "json",
+			content:    `{"level":"error","msg":"payment failed"}`,
+			want:       true,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "payment failed"}},
+		},
+		{
+			name:       "logfmt line matched by field rules",
+			format:     "logfmt",
+			fieldRules: []string{"level in (error, fatal)"},
+			content:    `level=error msg="payment failed" trace_id=abc err="card declined"`,
+			want:       true,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "payment failed"}, {Key: "trace_id", Value: "abc"}},
+		},
+		{
+			name:       "label selects the format",
+			format:     "text",
+			labels:     map[string]string{container.LabelLogFormat: "logfmt"},
+			fieldRules: []string{"status >= 500"},
+			content:    `level=info msg="ERROR page served" status=200`,
+			want:       false,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "ERROR page served"}},
+		},
+		{
+			name:       "invalid label falls back to the default format",
+			format:     "json",
+			labels:     map[string]string{container.LabelLogFormat: "xml"},
+			fieldRules: []string{"level == error"},
+			content:    `{"level":"error","msg":"payment failed"}`,
+			want:       true,
+			wantFields: []logfilter.Field{{Key: "msg", Value: "payment failed"}},
+		},
+		{
+			name:       "plain line in json format uses patterns",
+			format:     "json",
+			fieldRules: []string{"level == error"},
+			content:    "panic: ERROR before logger setup",
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watcher, err := New(NewMockContainerClient(), &WatcherOptions{
+				Interval:      time.Second,
+				ErrorPatterns: []string{"ERROR"},
+				LogFormat:     tt.format,
+				FieldRules:    tt.fieldRules,
+				AlertFields:   []string{"msg", "trace_id", "error"},
+			})
+			if err != nil {
+				t.Fatalf("failed to create watcher: %v", err)
+			}
+
+			c := container.Container{ID: "container1", Labels: tt.labels}
+			decision := watcher.Evaluate(c, 0, []byte(tt.content))
+			if decision.Matched != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", decision.Matched, tt.want)
+			}
+			if !reflect.DeepEqual(decision.Fields, tt.wantFields) {
+				t.Errorf("Evaluate() fields = %v, want %v", decision.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestNew_ruleEndpoints(t *testing.T) {
+	rules := []logfilter.RuleConfig{
+		{Name: "everywhere", Patterns: []string{"ERROR"}},
+		{Name: "edge", Patterns: []string{"disk full"}, Endpoints: []string{"edge-1", "edge-2"}},
+		{Name: "central", Patterns: []string{"replication lag"}, Endpoints: []string{"central"}},
+	}
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{host: "edge-1", want: []string{"everywhere", "edge"}},
+		{host: "central", want: []string{"everywhere", "central"}},
+		{host: "", want: []string{"everywhere"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			w, err := New(NewMockContainerClient(), &WatcherOptions{
+				Host:         tt.host,
+				Rules:        rules,
+				RuleExcludes: map[string][]string{"central": {"catching up"}},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got []string
+			for _, rule := range w.matcher.Rules {
+				got = append(got, rule.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected rules %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWatcher_processContainerLogs_rules(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+
+	client := NewMockContainerClient()
+	client.SetLogs(c.ID, []byte(
+		"2023-03-15T12:01:00.000000000Z WARN slow query\n"+
+			"2023-03-15T12:02:00.000000000Z ERROR deadlock detected\n"+
+			"2023-03-15T12:03:00.000000000Z ERROR timeout",
+	))
+
+	matcher, err := newMatcher(&WatcherOptions{
+		ErrorPatterns: []string{"ERROR"},
+		Rules: []logfilter.RuleConfig{
+			{Name: "db", Patterns: []string{"deadlock"}, Severity: logfilter.SeverityCritical},
+			{Name: "slow", Patterns: []string{"slow"}, Severity: logfilter.SeverityWarning},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to compile rules: %v", err)
+	}
+
+	watcher := &Watcher{
+		client:  client,
+		matcher: matcher,
+		offsets: map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+		C:       make(chan *MatchedLog, 10),
+	}
+
+	if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+		t.Fatalf("processContainerLogs failed: %v", err)
+	}
+	close(watcher.C)
+
+	// Rules are tried in order, so the error pattern rule wins over "db".
+	want := []struct {
+		rule     string
+		severity logfilter.Severity
+	}{
+		{rule: "slow", severity: logfilter.SeverityWarning},
+		{rule: "ERROR", severity: logfilter.SeverityError},
+		{rule: "ERROR", severity: logfilter.SeverityError},
+	}
+
+	var got []*MatchedLog
+	for match := range watcher.C {
+		got = append(got, match)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d matches, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Rule == nil || got[i].Rule.Name != want[i].rule {
+			t.Errorf("match %d: expected rule %s, got %v", i, want[i].rule, got[i].Rule)
+		}
+		if got[i].Severity() != want[i].severity {
+			t.Errorf("match %d: expected severity %s, got %s", i, want[i].severity, got[i].Severity())
+		}
+	}
+}
+
+func TestWatcher_processContainerLogs_streams(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+	logs := bytes.Join([][]byte{
+		frame(1, "2023-03-15T12:01:00.000000000Z ERROR: on stdout\n"),
+		frame(2, "2023-03-15T12:02:00.000000000Z DeprecationWarning: use run()\n"),
+		frame(1, "2023-03-15T12:03:00.000000000Z request handled\n"),
+	}, nil)
+
+	tests := []struct {
+		name string
+		opts WatcherOptions
+	}{
+		{name: "line by line"},
+		{name: "with context", opts: WatcherOptions{ContextBefore: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockContainerClient()
+			client.SetMultiplexedLogs(c.ID, logs)
+
+			opts := tt.opts
+			opts.ErrorPatterns = []string{"ERROR"}
+			opts.Rules = []logfilter.RuleConfig{{Name: "stderr", Stream: logfilter.StreamStderr}}
+			matcher, err := newMatcher(&opts)
+			if err != nil {
+				t.Fatalf("failed to compile rules: %v", err)
+			}
+
+			watcher := &Watcher{
+				client:  client,
+				matcher: matcher,
+				offsets: map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+				C:       make(chan *MatchedLog, 10),
+			}
+
+			if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+				t.Fatalf("processContainerLogs failed: %v", err)
+			}
+			close(watcher.C)
+
+			want := []struct {
+				content string
+				rule    string
+				stream  logfilter.Stream
+			}{
+				{content: "ERROR: on stdout", rule: "ERROR", stream: logfilter.StreamStdout},
+				{content: "DeprecationWarning: use run()", rule: "stderr", stream: logfilter.StreamStderr},
+			}
+
+			var got []*MatchedLog
+			for match := range watcher.C {
+				got = append(got, match)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("expected %d matches, got %d", len(want), len(got))
+			}
+			for i := range want {
+				if string(got[i].Line.Content) != want[i].content {
+					t.Errorf("match %d: expected %q, got %q", i, want[i].content, got[i].Line.Content)
+				}
+				if got[i].Rule == nil || got[i].Rule.Name != want[i].rule {
+					t.Errorf("match %d: expected rule %s, got %v", i, want[i].rule, got[i].Rule)
+				}
+				if got[i].Line.Stream != want[i].stream {
+					t.Errorf("match %d: expected stream %s, got %s", i, want[i].stream, got[i].Line.Stream)
+				}
+			}
+		})
+	}
+}
+
+func TestWatcher_processContainerLogs_sanitize(t *testing.T) {
+	c := container.Container{ID: "container1", Name: "test-container"}
+	logs := "2023-03-15T12:01:00.000000000Z \x1b[31mERROR\x1b[0m: Connection failed\r"
+
+	tests := []struct {
+		name     string
+		sanitize bool
+		want     []string
+	}{
+		{name: "sanitized", sanitize: true, want: []string{"ERROR: Connection failed"}},
+		{name: "raw", sanitize: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewMockContainerClient()
+			client.SetLogs(c.ID, []byte(logs))
+
+			matcher, err := newMatcher(&WatcherOptions{ErrorPatterns: []string{"^ERROR: .*failed$"}})
+			if err != nil {
+				t.Fatalf("failed to compile patterns: %v", err)
+			}
+
+			watcher := &Watcher{
+				client:   client,
+				matcher:  matcher,
+				sanitize: tt.sanitize,
+				offsets:  map[string]string{c.ID: "2023-03-15T12:00:00.000000000Z"},
+				C:        make(chan *MatchedLog, 10),
+			}
+
+			if err := watcher.processContainerLogs(context.Background(), c); err != nil {
+				t.Fatalf("processContainerLogs failed: %v", err)
+			}
+			close(watcher.C)
+
+			var got []string
+			for match := range watcher.C {
+				got = append(got, string(match.Line.Content))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}